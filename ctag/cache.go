@@ -0,0 +1,95 @@
+package ctag
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fieldMeta holds the precomputed tag metadata for a single struct field.
+// It is derived purely from the field's reflect.StructField and tag key, so it
+// can be shared between every value of the same struct type.
+type fieldMeta struct {
+	index     int                 // index is the position of the field in its struct.
+	field     reflect.StructField // field is the struct field the metadata describes.
+	matched   string              // matched is the tag key that supplied tag, or empty if the field is untagged.
+	tag       string              // tag is the raw tag value associated with the key.
+	name      string              // name is the parsed tag name.
+	options   []string            // options are the parsed tag options.
+	skip      bool                // skip reports whether the tag is "-".
+	omitempty bool                // omitempty reports whether the tag requests omitting zero values.
+	anonymous bool                // anonymous reports whether the field is embedded.
+	prefix    bool                // prefix reports whether the tag has the "prefix" option.
+	inline    bool                // inline reports whether the tag has the "inline" option.
+	err       error               // err is the error encountered parsing the tag, if any.
+	nameEnd   int                 // nameEnd is the total length of the Go names of the visible fields up to and including this one.
+}
+
+// typeMeta holds the precomputed metadata for the visible fields of a struct type.
+type typeMeta struct {
+	fields []fieldMeta
+}
+
+// cacheKey identifies a cached typeMeta by struct type, tag key and fallback keys.
+type cacheKey struct {
+	t         reflect.Type
+	key       string
+	fallbacks string // fallbacks are the fallback keys joined by NUL, which cannot appear in a tag key.
+}
+
+// typeCache maps cacheKey to *typeMeta. It is safe for concurrent use.
+var typeCache sync.Map
+
+// cachedTypeMeta returns the metadata for struct type t and tag key, computing
+// and storing it on first use. Fields without a tag for key use the tag of the
+// first of fallbacks they carry.
+func cachedTypeMeta(t reflect.Type, key string, fallbacks []string) *typeMeta {
+	ck := cacheKey{t: t, key: key, fallbacks: strings.Join(fallbacks, "\x00")}
+	if m, ok := typeCache.Load(ck); ok {
+		return m.(*typeMeta)
+	}
+	m, _ := typeCache.LoadOrStore(ck, buildTypeMeta(t, key, fallbacks))
+	return m.(*typeMeta)
+}
+
+// buildTypeMeta walks the fields of struct type t and parses the tag for key on each,
+// falling back to the tags for fallbacks in order. Unexported fields are dropped unless
+// they are embedded.
+func buildTypeMeta(t reflect.Type, key string, fallbacks []string) *typeMeta {
+	m := &typeMeta{fields: make([]fieldMeta, 0, t.NumField())}
+	nameEnd := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		matched, tagStr := key, f.Tag.Get(key)
+		for j := 0; tagStr == "" && j < len(fallbacks); j++ {
+			matched, tagStr = fallbacks[j], f.Tag.Get(fallbacks[j])
+		}
+		if tagStr == "" {
+			matched = ""
+		}
+
+		fm := fieldMeta{
+			index:     i,
+			field:     f,
+			matched:   matched,
+			tag:       tagStr,
+			skip:      tagStr == "-",
+			anonymous: f.Anonymous,
+		}
+		nameEnd += len(f.Name)
+		fm.nameEnd = nameEnd
+		if tagStr != "" {
+			fm.name, fm.options, fm.err = ParseTag(tagStr)
+			fm.omitempty = slices.Contains(fm.options, "omitempty")
+			fm.prefix = slices.Contains(fm.options, "prefix")
+			fm.inline = slices.Contains(fm.options, "inline")
+		}
+		m.fields = append(m.fields, fm)
+	}
+	return m
+}
@@ -0,0 +1,156 @@
+package ctag
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type benchAddress struct {
+	Street string `query:"street"`
+	City   string `query:"city"`
+	Zip    string `query:"zip,omitempty"`
+}
+
+type benchEmbedded struct {
+	Version int    `query:"version"`
+	Trace   string `query:"trace,omitempty"`
+}
+
+type benchRequest struct {
+	benchEmbedded
+	ID      int          `query:"id"`
+	Name    string       `query:"name"`
+	Active  bool         `query:"active"`
+	Tags    []string     `query:"tags,comma"`
+	Limit   *int         `query:"limit,omitempty"`
+	Address benchAddress `query:"address"`
+	Ignored string       `query:"-"`
+	Plain   string
+}
+
+// resetTypeCache clears all cached type metadata.
+func resetTypeCache() {
+	typeCache.Range(func(k, _ any) bool {
+		typeCache.Delete(k)
+		return true
+	})
+}
+
+func TestTypeCache(t *testing.T) {
+	resetTypeCache()
+
+	input := benchRequest{ID: 1, Name: "John"}
+	first, err := GetTags("query", input)
+	assert.NoError(t, err)
+
+	_, ok := typeCache.Load(cacheKey{t: reflect.TypeOf(input), key: "query"})
+	assert.True(t, ok)
+
+	second, err := GetTags("query", input)
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+}
+
+func TestTypeCacheKeys(t *testing.T) {
+	input := struct {
+		ID int `query:"id" body:"identifier,omitempty"`
+	}{ID: 7}
+
+	qtags, err := GetTags("query", input)
+	assertTags(t, CTags{{Key: "query", Name: "id", Field: 7}}, qtags, err)
+
+	btags, err := GetTags("body", input)
+	assertTags(t, CTags{{Key: "body", Name: "identifier", Options: []string{"omitempty"}, Field: 7}}, btags, err)
+}
+
+func TestTypeCacheOptionsIsolation(t *testing.T) {
+	input := struct {
+		IDs []int `query:"ids,comma"`
+	}{IDs: []int{1}}
+
+	tags, err := GetTags("query", input)
+	assert.NoError(t, err)
+	tags[0].Options[0] = "modified"
+
+	tags, err = GetTags("query", input)
+	assertTags(t, CTags{{Key: "query", Name: "ids", Options: []string{"comma"}, Field: []int{1}}}, tags, err)
+}
+
+func TestTypeCacheOmitEmptyOption(t *testing.T) {
+	input := struct {
+		Text  string `x:"text,note='no omitempty here'"`
+		Count int    `x:"omitempty_count"`
+		Skip  int    `x:"skip,omitempty"`
+	}{}
+
+	tags, err := GetTags("x", input)
+	assertTags(t, CTags{
+		{Key: "x", Name: "text", Options: []string{"note=no omitempty here"}, Field: ""},
+		{Key: "x", Name: "omitempty_count", Field: 0},
+	}, tags, err)
+}
+
+func TestTypeCacheConcurrent(t *testing.T) {
+	resetTypeCache()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			input := benchRequest{ID: id}
+			tags, err := GetTagsAndProcess("query", &input, &setFieldProcessor{})
+			assert.NoError(t, err)
+			assert.Len(t, tags, 8)
+			assert.Equal(t, "test_value", input.Name)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkGetTags(b *testing.B) {
+	input := benchRequest{ID: 1, Name: "John", Tags: []string{"a", "b"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetTags("query", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetTagsUncached(b *testing.B) {
+	input := benchRequest{ID: 1, Name: "John", Tags: []string{"a", "b"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resetTypeCache()
+		if _, err := GetTags("query", input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetTagsAndProcess(b *testing.B) {
+	input := benchRequest{ID: 1, Name: "John", Tags: []string{"a", "b"}}
+	p := &setFieldProcessor{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetTagsAndProcess("query", &input, p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetTagsAndProcessUncached(b *testing.B) {
+	input := benchRequest{ID: 1, Name: "John", Tags: []string{"a", "b"}}
+	p := &setFieldProcessor{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		resetTypeCache()
+		if _, err := GetTagsAndProcess("query", &input, p); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package ctag
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// getCollectionTags appends the tags of every struct held by the slice, array or map cv
+// to out, with element indexes in their paths. Map values are not addressable, so each is
+// traversed through a copy that is written back to the map when the map is settable.
+// Slices and maps already being traversed further up the current path are reported as cycles.
+func (w *walker) getCollectionTags(cv reflect.Value, path string, prefixes []string, depth int, active []bool, out []CTags) error {
+	switch cv.Kind() {
+	case reflect.Slice, reflect.Array:
+		if cv.Kind() == reflect.Slice && cv.Len() > 0 {
+			if !w.enter(cv.Pointer(), cv.Type()) {
+				return w.limit(&CycleError{Path: path, Type: cv.Type()})
+			}
+			defer w.leave(cv.Pointer(), cv.Type())
+		}
+		for i := 0; i < cv.Len(); i++ {
+			epath := path + "[" + strconv.Itoa(i) + "]"
+			eprefixes := w.elemPrefixes(prefixes, strconv.Itoa(i))
+			if err := w.getElemTags(cv.Index(i), epath, eprefixes, depth, active, out); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if cv.Len() == 0 {
+			return nil
+		}
+		if !w.enter(cv.Pointer(), cv.Type()) {
+			return w.limit(&CycleError{Path: path, Type: cv.Type()})
+		}
+		defer w.leave(cv.Pointer(), cv.Type())
+
+		for _, key := range sortedMapKeys(cv) {
+			epath := path + "[" + formatMapKey(key) + "]"
+			eprefixes := w.elemPrefixes(prefixes, fmt.Sprint(key.Interface()))
+			ev := cv.MapIndex(key)
+			if k := ev.Kind(); k == reflect.Ptr || k == reflect.Interface {
+				if err := w.getElemTags(ev, epath, eprefixes, depth, active, out); err != nil {
+					return err
+				}
+				continue
+			}
+
+			elem := reflect.New(ev.Type()).Elem()
+			elem.Set(ev)
+			if err := w.getElemTags(elem, epath, eprefixes, depth, active, out); err != nil {
+				return err
+			}
+			if cv.CanSet() {
+				cv.SetMapIndex(key, elem)
+			}
+		}
+	}
+	return nil
+}
+
+// getElemTags appends the tags of the collection element ev to out, dereferencing
+// pointers and interfaces. A struct held directly in a settable interface is traversed
+// through an addressable copy that is stored back. A collection nested in another is one
+// level deeper, as a nested struct is. Elements that are neither structs nor collections are ignored.
+func (w *walker) getElemTags(ev reflect.Value, path string, prefixes []string, depth int, active []bool, out []CTags) error {
+	for ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface {
+		if ev.IsNil() {
+			return nil
+		}
+		if ev.Kind() == reflect.Interface && ev.CanSet() && ev.Elem().Kind() == reflect.Struct {
+			elem := reflect.New(ev.Elem().Type()).Elem()
+			elem.Set(ev.Elem())
+			if err := w.getTags(elem, path, nil, prefixes, depth, active, out); err != nil {
+				return err
+			}
+			ev.Set(elem)
+			return nil
+		}
+		ev = ev.Elem()
+	}
+
+	switch ev.Kind() {
+	case reflect.Struct:
+		return w.getTags(ev, path, nil, prefixes, depth, active, out)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if !holdsStructs(ev.Type()) {
+			return nil
+		}
+		if !w.descend(depth) {
+			return w.limit(&DepthError{Path: path, MaxDepth: w.maxDepth})
+		}
+		return w.getCollectionTags(ev, path, prefixes, depth+1, active, out)
+	}
+	return nil
+}
+
+// holdsStructs reports whether t is a slice, array or map whose elements may be structs,
+// directly, through pointers or interfaces, or through further collections.
+func holdsStructs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		e := t.Elem()
+		for e.Kind() == reflect.Ptr {
+			e = e.Elem()
+		}
+		switch e.Kind() {
+		case reflect.Struct, reflect.Interface:
+			return true
+		}
+		return holdsStructs(e)
+	}
+	return false
+}
+
+// sortedMapKeys returns the keys of map m in a deterministic order, sorting strings,
+// integers and floats by value and other kinds by their formatted representation.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		}
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	})
+	return keys
+}
+
+// formatMapKey formats a map key for use in a field path. String keys are quoted.
+func formatMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return strconv.Quote(key.String())
+	}
+	return fmt.Sprint(key.Interface())
+}
@@ -0,0 +1,135 @@
+package ctag
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type collectionItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty,omitempty"`
+}
+
+type collectionMeta struct {
+	Value string `json:"value"`
+}
+
+type collectionOrder struct {
+	ID      int                         `json:"id"`
+	Items   []collectionItem            `json:"items"`
+	Ptrs    []*collectionItem           `json:"ptrs"`
+	Fixed   [2]collectionItem           `json:"fixed"`
+	Meta    map[string]collectionMeta   `json:"meta"`
+	PtrMeta map[int]*collectionMeta     `json:"ptr_meta"`
+	Grid    [][]collectionItem          `json:"grid"`
+	Any     []any                       `json:"any"`
+	Names   []string                    `json:"names"`
+	Skipped []collectionItem            `json:"-"`
+	Nested  map[string][]collectionMeta `json:"nested"`
+}
+
+func paths(tags CTags) []string {
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = tag.Path
+	}
+	return out
+}
+
+func newCollectionOrder() collectionOrder {
+	return collectionOrder{
+		ID:      1,
+		Items:   []collectionItem{{SKU: "a", Qty: 1}, {SKU: "b"}},
+		Ptrs:    []*collectionItem{{SKU: "p"}, nil},
+		Fixed:   [2]collectionItem{{SKU: "f0"}, {SKU: "f1"}},
+		Meta:    map[string]collectionMeta{"k2": {Value: "v2"}, "k1": {Value: "v1"}},
+		PtrMeta: map[int]*collectionMeta{2: {Value: "two"}, 1: {Value: "one"}},
+		Grid:    [][]collectionItem{{{SKU: "g"}}},
+		Any:     []any{collectionMeta{Value: "any"}, "ignored"},
+		Names:   []string{"x"},
+		Skipped: []collectionItem{{SKU: "s"}},
+		Nested:  map[string][]collectionMeta{"n": {{Value: "nv"}}},
+	}
+}
+
+func TestGetCollections(t *testing.T) {
+	input := newCollectionOrder()
+
+	tags, err := Get("json", input)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Items", "Ptrs", "Fixed", "Meta", "PtrMeta", "Grid", "Any", "Names", "Nested"}, paths(tags))
+
+	tags, err = Get("json", input, WithCollections(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"ID",
+		"Items", "Items[0].SKU", "Items[0].Qty", "Items[1].SKU",
+		"Ptrs", "Ptrs[0].SKU",
+		"Fixed", "Fixed[0].SKU", "Fixed[1].SKU",
+		"Meta", `Meta["k1"].Value`, `Meta["k2"].Value`,
+		"PtrMeta", "PtrMeta[1].Value", "PtrMeta[2].Value",
+		"Grid", "Grid[0][0].SKU",
+		"Any", "Any[0].Value",
+		"Names",
+		"Nested", `Nested["n"][0].Value`,
+	}, paths(tags))
+
+	sku := tags.Find(func(tag CTag) bool { return tag.Path == "Items[1].SKU" })
+	assert.Equal(t, "b", sku.Field)
+	assert.Equal(t, []int{0}, sku.Index)
+}
+
+func TestGetCollectionsMaxDepth(t *testing.T) {
+	input := newCollectionOrder()
+
+	tags, err := Get("json", input, WithCollections(true), WithMaxDepth(1))
+	assert.NoError(t, err)
+	assert.Contains(t, paths(tags), "Items[0].SKU")
+
+	tags, err = Get("json", input, WithCollections(true), WithNested(false))
+	assert.NoError(t, err)
+	assert.NotContains(t, paths(tags), "Items[0].SKU")
+}
+
+type skuProcessor struct{}
+
+func (p *skuProcessor) Process(field any, tag *CTag) error {
+	if tag.Name == "sku" || tag.Name == "value" {
+		return SetField(field, "set_"+tag.Path)
+	}
+	return nil
+}
+
+func TestGetCollectionsProcess(t *testing.T) {
+	input := newCollectionOrder()
+
+	_, err := Get("json", &input, WithCollections(true), WithProcessor(&skuProcessor{}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "set_Items[0].SKU", input.Items[0].SKU)
+	assert.Equal(t, "set_Items[1].SKU", input.Items[1].SKU)
+	assert.Equal(t, "set_Ptrs[0].SKU", input.Ptrs[0].SKU)
+	assert.Equal(t, "set_Fixed[1].SKU", input.Fixed[1].SKU)
+	assert.Equal(t, `set_Meta["k1"].Value`, input.Meta["k1"].Value)
+	assert.Equal(t, "set_PtrMeta[2].Value", input.PtrMeta[2].Value)
+	assert.Equal(t, "set_Grid[0][0].SKU", input.Grid[0][0].SKU)
+	assert.Equal(t, collectionMeta{Value: "set_Any[0].Value"}, input.Any[0])
+	assert.Equal(t, `set_Nested["n"][0].Value`, input.Nested["n"][0].Value)
+	assert.Equal(t, "s", input.Skipped[0].SKU)
+}
+
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func TestHoldsStructs(t *testing.T) {
+	assert.True(t, holdsStructs(typeOf[[]collectionItem]()))
+	assert.True(t, holdsStructs(typeOf[[3]*collectionItem]()))
+	assert.True(t, holdsStructs(typeOf[map[string][]collectionItem]()))
+	assert.True(t, holdsStructs(typeOf[[]any]()))
+	assert.False(t, holdsStructs(typeOf[[]byte]()))
+	assert.False(t, holdsStructs(typeOf[map[string][]string]()))
+	assert.False(t, holdsStructs(typeOf[collectionItem]()))
+}
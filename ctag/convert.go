@@ -0,0 +1,131 @@
+package ctag
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Converter converts a value to a target type for SetField. It is registered in a Converters
+// registry for a source type and a target type, and is only called with values of the source type.
+// The returned value must be assignable to the target type.
+//
+// Example usage:
+//
+//	var toMoney Converter = func(value any) (any, error) {
+//	    return ParseMoney(value.(string))
+//	}
+type Converter func(value any) (any, error)
+
+// converterKey identifies a Converter by source and target type.
+type converterKey struct {
+	from reflect.Type
+	to   reflect.Type
+}
+
+// Converters is a registry of Converter functions keyed by source and target type.
+// It is safe for concurrent use. SetField consults the registry passed with WithConverters,
+// then DefaultConverters, before any built-in conversion.
+//
+// Example usage:
+//
+//	converters := NewConverters()
+//	RegisterConverter(converters, func(s string) (TenantID, error) {
+//	    return ParseTenantID(s)
+//	})
+//
+//	err := SetField(&request.Tenant, "acme", WithConverters(converters))
+type Converters struct {
+	mu    sync.RWMutex
+	funcs map[converterKey]Converter
+}
+
+// DefaultConverters is the registry consulted by every call to SetField.
+// Converters registered here apply globally; use WithConverters for per-call conversions.
+var DefaultConverters = NewConverters()
+
+// NewConverters returns an empty Converters registry.
+func NewConverters() *Converters {
+	return &Converters{funcs: make(map[converterKey]Converter)}
+}
+
+// Register registers conv to convert values of type from to type to, replacing any
+// Converter already registered for the pair. A nil conv removes the registration.
+//
+// Converters are looked up by the dynamic type of the value being set, which is never an
+// interface type, so Register panics if from is an interface type such as fmt.Stringer or any.
+//
+// Example usage:
+//
+//	DefaultConverters.Register(reflect.TypeOf(""), reflect.TypeOf(Money{}), func(value any) (any, error) {
+//	    return ParseMoney(value.(string))
+//	})
+func (c *Converters) Register(from, to reflect.Type, conv Converter) {
+	if from.Kind() == reflect.Interface {
+		panic(fmt.Sprintf("ctag: converter source type %v is an interface and would never match a value", from))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := converterKey{from: from, to: to}
+	if conv == nil {
+		delete(c.funcs, key)
+		return
+	}
+	c.funcs[key] = conv
+}
+
+// Lookup returns the Converter registered to convert values of type from to type to.
+// A nil registry has no converters.
+func (c *Converters) Lookup(from, to reflect.Type) (Converter, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	conv, ok := c.funcs[converterKey{from: from, to: to}]
+	return conv, ok
+}
+
+// RegisterConverter registers fn in c to convert values of type S to type T.
+// It is a typed alternative to Converters.Register, and like it panics if S is an interface type.
+//
+// Example usage:
+//
+//	RegisterConverter(DefaultConverters, func(s string) (Money, error) {
+//	    return ParseMoney(s)
+//	})
+func RegisterConverter[S, T any](c *Converters, fn func(S) (T, error)) {
+	from := reflect.TypeOf((*S)(nil)).Elem()
+	to := reflect.TypeOf((*T)(nil)).Elem()
+	c.Register(from, to, func(value any) (any, error) {
+		return fn(value.(S))
+	})
+}
+
+// setConverted sets fieldVal from value with the Converter registered for their types, first
+// in the registry set with WithConverters and then in DefaultConverters, and reports whether
+// one was found.
+func (s *setter) setConverted(fieldVal reflect.Value, value any) (bool, error) {
+	from, to := reflect.TypeOf(value), fieldVal.Type()
+	conv, ok := s.converters.Lookup(from, to)
+	if !ok {
+		if conv, ok = DefaultConverters.Lookup(from, to); !ok {
+			return false, nil
+		}
+	}
+
+	out, err := conv(value)
+	if err != nil {
+		return true, &ConversionError{Value: value, Type: to, Err: err}
+	}
+	if out == nil {
+		fieldVal.Set(reflect.Zero(to))
+		return true, nil
+	}
+	outVal := reflect.ValueOf(out)
+	if !outVal.Type().AssignableTo(to) {
+		return true, &ConversionError{Value: value, Type: to, Err: fmt.Errorf("converter returned %T", out)}
+	}
+	fieldVal.Set(outVal)
+	return true, nil
+}
@@ -0,0 +1,160 @@
+package ctag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type convertMoney struct {
+	Cents    int64
+	Currency string
+}
+
+type convertTenant string
+
+type convertOrder struct {
+	Total   convertMoney   `json:"total"`
+	Tenant  convertTenant  `json:"tenant"`
+	Refunds []convertMoney `json:"refunds"`
+}
+
+func parseMoney(s string) (convertMoney, error) {
+	amount, currency, ok := strings.Cut(s, " ")
+	if !ok {
+		return convertMoney{}, fmt.Errorf("missing currency in %q", s)
+	}
+	cents, err := strconv.ParseInt(strings.Replace(amount, ".", "", 1), 10, 64)
+	if err != nil {
+		return convertMoney{}, err
+	}
+	return convertMoney{Cents: cents, Currency: currency}, nil
+}
+
+func TestSetFieldConverters(t *testing.T) {
+	RegisterConverter(DefaultConverters, parseMoney)
+	t.Cleanup(func() {
+		DefaultConverters.Register(typeOf[string](), typeOf[convertMoney](), nil)
+	})
+
+	tenants := NewConverters()
+	RegisterConverter(tenants, func(s string) (convertTenant, error) {
+		return convertTenant("tenant-" + s), nil
+	})
+
+	override := NewConverters()
+	RegisterConverter(override, func(s string) (convertMoney, error) {
+		return convertMoney{Currency: s}, nil
+	})
+
+	tests := []setFieldCase{
+		{
+			name:     "default registry",
+			field:    func() any { return new(convertMoney) },
+			value:    "12.50 EUR",
+			expected: convertMoney{Cents: 1250, Currency: "EUR"},
+		},
+		{
+			name:     "pointer field",
+			field:    func() any { return new(*convertMoney) },
+			value:    "1.00 USD",
+			expected: &convertMoney{Cents: 100, Currency: "USD"},
+		},
+		{
+			name:     "slice elements",
+			field:    func() any { return new([]convertMoney) },
+			value:    "1.00 USD,2.00 USD",
+			expected: []convertMoney{{Cents: 100, Currency: "USD"}, {Cents: 200, Currency: "USD"}},
+		},
+		{
+			name:  "struct fields from map",
+			field: func() any { return new(convertOrder) },
+			value: map[string]any{"total": "3.00 GBP", "tenant": "acme", "refunds": []string{"1.00 GBP"}},
+			opts:  []SetOption{WithConverters(tenants)},
+			expected: convertOrder{
+				Total:   convertMoney{Cents: 300, Currency: "GBP"},
+				Tenant:  "tenant-acme",
+				Refunds: []convertMoney{{Cents: 100, Currency: "GBP"}},
+			},
+		},
+		{
+			name:     "per-call registry without override",
+			field:    func() any { return new(convertTenant) },
+			value:    "acme",
+			expected: convertTenant("acme"),
+		},
+		{
+			name:     "per-call registry",
+			field:    func() any { return new(convertTenant) },
+			value:    "acme",
+			opts:     []SetOption{WithConverters(tenants)},
+			expected: convertTenant("tenant-acme"),
+		},
+		{
+			name:     "per-call registry overrides default",
+			field:    func() any { return new(convertMoney) },
+			value:    "EUR",
+			opts:     []SetOption{WithConverters(override)},
+			expected: convertMoney{Currency: "EUR"},
+		},
+		{
+			name:   "converter error",
+			field:  func() any { return new(convertMoney) },
+			value:  "12.50",
+			errMsg: `ctag: cannot parse "12.50" as ctag.convertMoney: missing currency in "12.50"`,
+		},
+		{
+			name:   "unregistered source type",
+			field:  func() any { return new(convertMoney) },
+			value:  1250,
+			errMsg: "ctag: cannot convert int to ctag.convertMoney",
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestConvertersRegister(t *testing.T) {
+	c := NewConverters()
+	from, to := typeOf[int](), typeOf[string]()
+
+	_, ok := c.Lookup(from, to)
+	assert.False(t, ok)
+
+	c.Register(from, to, func(value any) (any, error) {
+		return 42, nil
+	})
+	_, ok = c.Lookup(from, to)
+	assert.True(t, ok)
+
+	var s string
+	err := SetField(&s, 1, WithConverters(c))
+	assert.ErrorContains(t, err, "ctag: cannot convert int to string: converter returned int")
+
+	c.Register(from, to, nil)
+	_, ok = c.Lookup(from, to)
+	assert.False(t, ok)
+
+	var nilRegistry *Converters
+	_, ok = nilRegistry.Lookup(from, to)
+	assert.False(t, ok)
+	assert.NoError(t, SetField(&s, 1, WithConverters(nilRegistry)))
+	assert.Equal(t, "1", s)
+}
+
+func TestConvertersRegisterInterfaceSource(t *testing.T) {
+	c := NewConverters()
+	assert.PanicsWithValue(t, "ctag: converter source type fmt.Stringer is an interface and would never match a value", func() {
+		RegisterConverter(c, func(s fmt.Stringer) (string, error) {
+			return s.String(), nil
+		})
+	})
+	assert.Panics(t, func() {
+		c.Register(typeOf[any](), typeOf[string](), func(value any) (any, error) {
+			return fmt.Sprint(value), nil
+		})
+	})
+}
@@ -3,8 +3,10 @@ package ctag
 import (
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // CTag represents a parsed tag associated with a struct field.
@@ -12,10 +14,20 @@ import (
 //
 // Fields:
 //
-//	Key     - The primary identifier in a struct tag, used to retrieve the tag.
-//	Name    - The first value associated with the Key in the tag, typically used to describe the purpose or content.
-//	Options - Additional comma-separated values associated with the Key, providing further instructions or modifiers.
-//	Field   - The actual data value of the struct field.
+//	Key         - The primary identifier in a struct tag, used to retrieve the tag.
+//	Name        - The first value associated with the Key in the tag, typically used to describe the purpose or content.
+//	Options     - Additional comma-separated values associated with the Key, providing further instructions or modifiers.
+//	MatchedKey  - The tag key that actually supplied Name and Options. It differs from Key when a fallback key
+//	              matched, and is empty when the field is untagged.
+//	Field       - The actual data value of the struct field.
+//	FieldName   - The Go name of the struct field.
+//	Path        - The dotted Go path to the field from the root struct, including nested and embedded parents,
+//	              and element indexes such as Items[2].SKU or Meta["k"].Value.
+//	Index       - The index sequence of the field from the root struct, as used by reflect.Value.FieldByIndex.
+//	              For fields reached through a slice, array or map element, it is relative to the element struct.
+//	StructField - The reflect.StructField describing the field.
+//
+// The name and options may be single-quoted to include commas, quotes or spaces, as described by ParseTag.
 //
 // Example:
 //
@@ -28,12 +40,21 @@ import (
 //	Key = "body"
 //	Name = "text"
 //	Options = ["comma", "omitempty"]
+//	MatchedKey = "body"
 //	Field contains the actual data of the string field 'IDs'.
+//	FieldName = "IDs"
+//	Path = "IDs"
+//	Index = [0]
 type CTag struct {
-	Key     string   // Key is the primary identifier in a struct tag.
-	Name    string   // Name is the first value associated with Key in the tag.
-	Options []string // Options are additional values associated with Key.
-	Field   any      // Field is the data value of the struct field.
+	Key         string              // Key is the primary identifier in a struct tag.
+	Name        string              // Name is the first value associated with Key in the tag.
+	Options     []string            // Options are additional values associated with Key.
+	MatchedKey  string              // MatchedKey is the tag key that supplied Name and Options.
+	Field       any                 // Field is the data value of the struct field.
+	FieldName   string              // FieldName is the Go name of the struct field.
+	Path        string              // Path is the dotted Go path to the field from the root struct.
+	Index       []int               // Index is the index sequence of the field from the root struct.
+	StructField reflect.StructField // StructField describes the struct field.
 }
 
 // TagProcessor defines an interface for custom processing of fields based on their associated tags.
@@ -54,8 +75,120 @@ type TagProcessor interface {
 // common operations on the collection of tags in a more idiomatic and readable way.
 type CTags []CTag
 
+// Get retrieves all tags for key from a struct, configured by the provided options.
+// GetTags, GetTagsAndProcess and GetTagsAndProcessAll are convenience wrappers around Get.
+//
+// By default, a field is skipped if:
+//   - The field has no tag for the key, unless WithUntagged is set
+//   - The tag name is "-", indicating the field should not be serialized or processed.
+//   - The tag contains "omitempty" and the field's value is the zero value for that type, as controlled by WithOmitEmpty
+//
+// Nested struct fields are descended into, as controlled by WithNested and WithMaxDepth,
+// and embedded structs are flattened into their parent.
+//
+// Parameters:
+//
+//	key  - the tag key to search for in the struct tags
+//	data - the struct from which tags should be extracted, must be a struct or a pointer to one
+//	opts - options configuring the extraction, such as WithProcessor or WithErrorMode
+//
+// Returns:
+//
+//	A slice of CTag containing all extracted tags, or an error if the input is not a struct or the processing fails.
+//	With CollectErrors, the tags that succeeded are returned along with a FieldErrors error.
+//
+// Example usage:
+//
+//	type Request struct {
+//	    ID     int    `query:"id"`
+//	    Name   string `query:"name,omitempty"`
+//	    Filter struct {
+//	        Status string `query:"status"`
+//	    } `query:"filter"`
+//	}
+//
+//	var request Request
+//	tags, err := Get("query", &request,
+//	    WithProcessor(&QueryProcessor{req: req}),
+//	    WithNested(false),
+//	    WithErrorMode(CollectErrors),
+//	)
+func Get(key string, data any, opts ...Option) (CTags, error) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w; got: %T", ErrNotStruct, data)
+	}
+	w := newWalker([]string{key}, newOptions(opts))
+	tags, err := w.run(v)
+	if tags == nil {
+		return nil, err
+	}
+	return tags[0], err
+}
+
+// GetMulti retrieves the tags for several keys from a struct in a single traversal,
+// configured by the provided options. Each key sees exactly the fields it would see
+// in its own call to Get; fields are visited once and processed for each key in turn.
+//
+// WithProcessors assigns a TagProcessor to individual keys. Keys without an entry use
+// the processor set with WithProcessor, if any. Duplicate keys are ignored.
+//
+// Parameters:
+//
+//	keys - the tag keys to search for in the struct tags
+//	data - the struct from which tags should be extracted, must be a struct or a pointer to one
+//	opts - options configuring the extraction, such as WithProcessors or WithErrorMode
+//
+// Returns:
+//
+//	A map from each key to its extracted tags, or an error if the input is not a struct or the processing fails.
+//	With CollectErrors, the tags that succeeded are returned along with a FieldErrors error.
+//
+// Example usage:
+//
+//	type Request struct {
+//	    ID    int    `path:"id"`
+//	    Limit int    `query:"limit"`
+//	    Token string `header:"Authorization"`
+//	}
+//
+//	var request Request
+//	tags, err := GetMulti([]string{"path", "query", "header"}, &request,
+//	    WithProcessors(map[string]TagProcessor{
+//	        "path":   &PathProcessor{req: req},
+//	        "query":  &QueryProcessor{req: req},
+//	        "header": &HeaderProcessor{req: req},
+//	    }),
+//	)
+//	// tags["query"][0].Name = "limit"
+func GetMulti(keys []string, data any, opts ...Option) (map[string]CTags, error) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w; got: %T", ErrNotStruct, data)
+	}
+
+	var unique []string
+	for _, key := range keys {
+		if !slices.Contains(unique, key) {
+			unique = append(unique, key)
+		}
+	}
+
+	w := newWalker(unique, newOptions(opts))
+	tags, err := w.run(v)
+	if tags == nil {
+		return nil, err
+	}
+
+	grouped := make(map[string]CTags, len(unique))
+	for k, key := range unique {
+		grouped[key] = tags[k]
+	}
+	return grouped, err
+}
+
 // GetTags retrieves all tags from a struct without additional processing.
-// This function is a convenience wrapper around GetTagsWithProcessor, using nil as the processor
+// This function is a convenience wrapper around Get, using no processor
 // to perform no additional processing after parsing the tag.
 //
 // A field is skipped if:
@@ -93,11 +226,12 @@ type CTags []CTag
 //	    fmt.Printf("Tags: %+v\n", tags)
 //	}
 func GetTags(key string, data any) (CTags, error) {
-	return GetTagsAndProcess(key, data, nil)
+	return Get(key, data)
 }
 
 // GetTagsAndProcess retrieves and processes all tags from a struct.
 // It allows for custom processing of each tag via a provided TagProcessor.
+// This function is a convenience wrapper around Get using WithProcessor.
 //
 // A field is skipped if:
 //   - The tag name is an empty string
@@ -143,11 +277,40 @@ func GetTags(key string, data any) (CTags, error) {
 //	    fmt.Printf("Processed Tags: %+v\n", tags)
 //	}
 func GetTagsAndProcess(key string, data any, processor TagProcessor) (CTags, error) {
-	v := reflect.Indirect(reflect.ValueOf(data))
-	if v.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("ctag: expected input to be a struct; got: %T", data)
-	}
-	return getTags(key, v, processor)
+	return Get(key, data, WithProcessor(processor))
+}
+
+// GetTagsAndProcessAll retrieves and processes all tags from a struct like GetTagsAndProcess,
+// but keeps going when a field fails instead of returning on the first error.
+// This function is a convenience wrapper around Get using WithProcessor and WithErrorMode(CollectErrors).
+//
+// Every field is visited and processed. Fields whose tag is malformed or whose processing fails
+// are left out of the returned tags and reported together as a FieldErrors value, which
+// unwraps to the individual *FieldError values in the same way as errors.Join.
+//
+// Parameters:
+//
+//	key       - the tag key to search for in the struct tags
+//	data      - the struct from which tags should be extracted, must be a struct
+//	processor - a TagProcessor to apply custom processing to each extracted tag
+//
+// Returns:
+//
+//	A slice of CTag containing all successfully processed tags, and a FieldErrors error if any field failed,
+//	or an error if the input is not a struct.
+//
+// Example usage:
+//
+//	tags, err := GetTagsAndProcessAll("query", &request, processor)
+//
+//	var fieldErrs FieldErrors
+//	if errors.As(err, &fieldErrs) {
+//	    for _, fe := range fieldErrs {
+//	        fmt.Printf("%s: %v\n", fe.Name, fe.Err)
+//	    }
+//	}
+func GetTagsAndProcessAll(key string, data any, processor TagProcessor) (CTags, error) {
+	return Get(key, data, WithProcessor(processor), WithErrorMode(CollectErrors))
 }
 
 // Filter returns a new CTags slice containing only the tags that satisfy the
@@ -310,370 +473,662 @@ func (t *CTag) String() string {
 	return fmt.Sprintf("CTag(Key=%s, Name=%s, Options=[%s], Field=%+v)", t.Key, t.Name, options, t.Field)
 }
 
-func SetField(field any, value any) error {
-	fieldVal := reflect.ValueOf(field)
-	if fieldVal.Kind() != reflect.Ptr {
-		return fmt.Errorf("ctag: field must be a pointer, got %T", field)
-	}
-	if fieldVal.IsNil() {
-		return fmt.Errorf("ctag: field pointer is nil")
-	}
+// HasOption reports whether the CTag has an option with the given name, either as a
+// bare flag such as "omitempty" or as a key=value pair such as "default=10".
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	true if the option is present, false otherwise.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "query", Name: "limit", Options: []string{"omitempty", "default=10"}}
+//
+//	tag.HasOption("omitempty") // true
+//	tag.HasOption("default")   // true
+//	tag.HasOption("min")       // false
+func (t *CTag) HasOption(name string) bool {
+	_, ok := t.Option(name)
+	return ok
+}
 
-	fieldElem := fieldVal.Elem()
-	if !fieldElem.CanSet() {
-		return fmt.Errorf("ctag: field is not settable")
+// Option returns the value of the first option with the given name.
+// A bare flag is present with an empty value.
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	The option value and true if the option is present, or an empty string and false otherwise.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "query", Name: "created", Options: []string{"layout=2006-01-02"}}
+//
+//	layout, ok := tag.Option("layout") // "2006-01-02", true
+func (t *CTag) Option(name string) (string, bool) {
+	for _, opt := range t.Options {
+		if k, v, _ := strings.Cut(opt, "="); k == name {
+			return v, true
+		}
 	}
-
-	return setValue(fieldElem, value)
+	return "", false
 }
 
-func setValue(fieldVal reflect.Value, value any) error {
-	if value == nil {
-		fieldVal.Set(reflect.Zero(fieldVal.Type()))
-		return nil
+// OptionInt returns the value of the named option parsed as an int.
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	The parsed value and true if the option is present, or 0 and false otherwise.
+//	An error is returned if the option is a bare flag or its value is not a valid int.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "query", Name: "limit", Options: []string{"min=1", "max=100"}}
+//
+//	min, ok, err := tag.OptionInt("min") // 1, true, nil
+func (t *CTag) OptionInt(name string) (int, bool, error) {
+	v, ok, err := t.optionValue(name)
+	if !ok || err != nil {
+		return 0, ok, err
 	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, true, fmt.Errorf("ctag: option %q: cannot parse %q as int: %w", name, v, err)
+	}
+	return i, true, nil
+}
 
-	valueVal := reflect.ValueOf(value)
-	fieldType := fieldVal.Type()
-
-	if valueVal.Type().AssignableTo(fieldType) {
-		fieldVal.Set(valueVal)
-		return nil
+// OptionFloat returns the value of the named option parsed as a float64.
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	The parsed value and true if the option is present, or 0 and false otherwise.
+//	An error is returned if the option is a bare flag or its value is not a valid float.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "query", Name: "ratio", Options: []string{"max=0.5"}}
+//
+//	max, ok, err := tag.OptionFloat("max") // 0.5, true, nil
+func (t *CTag) OptionFloat(name string) (float64, bool, error) {
+	v, ok, err := t.optionValue(name)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, true, fmt.Errorf("ctag: option %q: cannot parse %q as float: %w", name, v, err)
 	}
+	return f, true, nil
+}
 
-	switch fieldType.Kind() {
-	case reflect.Ptr:
-		return setPointerValue(fieldVal, value)
-	case reflect.Interface:
-		fieldVal.Set(valueVal)
-		return nil
-	case reflect.Struct:
-		return setStructValue(fieldVal, value)
-	case reflect.Slice:
-		return setSliceValue(fieldVal, value)
-	case reflect.Map:
-		return fmt.Errorf("ctag: cannot convert %T to %v", value, fieldType)
-	case reflect.String:
-		fieldVal.SetString(fmt.Sprintf("%v", value))
-		return nil
+// OptionBool returns the value of the named option parsed as a bool.
+// A bare flag is treated as true.
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	The parsed value and true if the option is present, or false and false otherwise.
+//	An error is returned if the option value is not a valid bool.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "query", Name: "active", Options: []string{"required", "trim=false"}}
+//
+//	required, ok, err := tag.OptionBool("required") // true, true, nil
+//	trim, ok, err := tag.OptionBool("trim")         // false, true, nil
+func (t *CTag) OptionBool(name string) (bool, bool, error) {
+	for _, opt := range t.Options {
+		k, v, hasValue := strings.Cut(opt, "=")
+		if k != name {
+			continue
+		}
+		if !hasValue {
+			return true, true, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, true, fmt.Errorf("ctag: option %q: cannot parse %q as bool: %w", name, v, err)
+		}
+		return b, true, nil
 	}
+	return false, false, nil
+}
 
-	if valueVal.Kind() == reflect.String {
-		return setFromString(fieldVal, valueVal.String())
+// OptionDuration returns the value of the named option parsed with time.ParseDuration.
+//
+// Parameters:
+//
+//	name - the option name to look for
+//
+// Returns:
+//
+//	The parsed value and true if the option is present, or 0 and false otherwise.
+//	An error is returned if the option is a bare flag or its value is not a valid duration.
+//
+// Example usage:
+//
+//	tag := CTag{Key: "env", Name: "TIMEOUT", Options: []string{"default=30s"}}
+//
+//	timeout, ok, err := tag.OptionDuration("default") // 30 * time.Second, true, nil
+func (t *CTag) OptionDuration(name string) (time.Duration, bool, error) {
+	v, ok, err := t.optionValue(name)
+	if !ok || err != nil {
+		return 0, ok, err
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, true, fmt.Errorf("ctag: option %q: cannot parse %q as duration: %w", name, v, err)
 	}
+	return d, true, nil
+}
 
-	if isNumeric(valueVal.Kind()) && isNumeric(fieldType.Kind()) {
-		return setNumericValue(fieldVal, valueVal)
+// optionValue returns the value of the first key=value option with the given name.
+// It returns an error if the option is present only as a bare flag.
+func (t *CTag) optionValue(name string) (string, bool, error) {
+	for _, opt := range t.Options {
+		k, v, hasValue := strings.Cut(opt, "=")
+		if k != name {
+			continue
+		}
+		if !hasValue {
+			return "", true, fmt.Errorf("ctag: option %q has no value", name)
+		}
+		return v, true, nil
 	}
+	return "", false, nil
+}
 
-	return fmt.Errorf("ctag: cannot convert %T to %v", value, fieldType)
+// walker holds the configuration and accumulated state of a single tag traversal.
+// A traversal extracts tags for one or more keys at once.
+type walker struct {
+	options
+	keys     []string                           // keys are the tag keys being extracted.
+	errs     FieldErrors                        // errs holds the field errors accumulated with CollectErrors.
+	visiting map[visit]struct{}                 // visiting holds the structs and maps on the current traversal path.
+	types    map[reflect.Type]int               // types counts the struct types on the current traversal path with WithAllocate.
+	promoted map[promoteKey]map[string]struct{} // promoted caches the fields shadowed in each namespace with EmbedPromote.
 }
 
-func setPointerValue(fieldVal reflect.Value, value any) error {
-	if fieldVal.IsNil() {
-		fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+// newWalker returns a walker extracting the given keys with the given options.
+func newWalker(keys []string, o options) *walker {
+	w := &walker{keys: keys, options: o}
+	if o.allocate {
+		w.types = make(map[reflect.Type]int)
 	}
-	return setValue(fieldVal.Elem(), value)
+	return w
 }
 
-func setSliceValue(fieldVal reflect.Value, value any) error {
-	valueVal := reflect.ValueOf(value)
-
-	if valueVal.Type().AssignableTo(fieldVal.Type()) {
-		fieldVal.Set(valueVal)
+// fail records a field error. With CollectErrors the error is accumulated and nil is
+// returned so that the traversal continues; otherwise the error is returned as is.
+func (w *walker) fail(err *FieldError) error {
+	if w.errorMode == CollectErrors {
+		w.errs = append(w.errs, err)
 		return nil
 	}
+	return err
+}
 
-	switch valueVal.Kind() {
-	case reflect.String:
-		return setSliceFromString(fieldVal, valueVal.String())
-	case reflect.Slice:
-		return setSliceFromSlice(fieldVal, valueVal)
+// run traverses the struct value v and returns the extracted tags for each key, in the
+// order of w.keys, along with any accumulated field errors.
+func (w *walker) run(v reflect.Value) ([]CTags, error) {
+	active := make([]bool, len(w.keys))
+	for k := range active {
+		active[k] = true
 	}
 
-	if valueVal.Type().AssignableTo(fieldVal.Type().Elem()) {
-		slice := reflect.MakeSlice(fieldVal.Type(), 1, 1)
-		slice.Index(0).Set(valueVal)
-		fieldVal.Set(slice)
-		return nil
+	out := make([]CTags, len(w.keys))
+	if err := w.getTags(v, "", nil, nil, 0, active, out); err != nil {
+		return nil, err
+	}
+	if len(w.errs) > 0 {
+		return out, w.errs
 	}
+	return out, nil
+}
 
-	return fmt.Errorf("ctag: cannot convert %T to %v", value, fieldVal.Type())
+// visit identifies a struct or map on the current traversal path by address and type.
+// The type is needed because an embedded or nested struct may share its parent's address.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
 }
 
-func setSliceFromString(fieldVal reflect.Value, str string) error {
-	if str == "" {
-		fieldVal.Set(reflect.MakeSlice(fieldVal.Type(), 0, 0))
-		return nil
+// enter marks the value at ptr with type t as being on the current traversal path.
+// It reports false if the value is already on the path, meaning the data contains a cycle.
+func (w *walker) enter(ptr uintptr, t reflect.Type) bool {
+	if w.visiting == nil {
+		w.visiting = make(map[visit]struct{})
 	}
-
-	parts := strings.Split(str, ",")
-	slice := reflect.MakeSlice(fieldVal.Type(), len(parts), len(parts))
-
-	for i, part := range parts {
-		if err := setValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
-			return fmt.Errorf("ctag: error converting slice element %d: %w", i, err)
-		}
+	id := visit{ptr: ptr, typ: t}
+	if _, ok := w.visiting[id]; ok {
+		return false
 	}
-
-	fieldVal.Set(slice)
-	return nil
+	w.visiting[id] = struct{}{}
+	return true
 }
 
-func setFromString(fieldVal reflect.Value, str string) error {
-	switch fieldVal.Kind() {
-	case reflect.String:
-		fieldVal.SetString(str)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		val, err := strconv.ParseInt(str, 10, 64)
-		if err != nil {
-			return fmt.Errorf("ctag: cannot parse %q as int: %w", str, err)
-		}
-		fieldVal.SetInt(val)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		val, err := strconv.ParseUint(str, 10, 64)
-		if err != nil {
-			return fmt.Errorf("ctag: cannot parse %q as uint: %w", str, err)
-		}
-		fieldVal.SetUint(val)
-	case reflect.Float32, reflect.Float64:
-		val, err := strconv.ParseFloat(str, 64)
-		if err != nil {
-			return fmt.Errorf("ctag: cannot parse %q as float: %w", str, err)
-		}
-		fieldVal.SetFloat(val)
-	case reflect.Bool:
-		val, err := strconv.ParseBool(str)
-		if err != nil {
-			return fmt.Errorf("ctag: cannot parse %q as bool: %w", str, err)
-		}
-		fieldVal.SetBool(val)
-	default:
-		return fmt.Errorf("ctag: cannot convert string to %v", fieldVal.Type())
-	}
-	return nil
+// leave removes the value at ptr with type t from the current traversal path.
+func (w *walker) leave(ptr uintptr, t reflect.Type) {
+	delete(w.visiting, visit{ptr: ptr, typ: t})
 }
 
-func setNumericValue(fieldVal reflect.Value, valueVal reflect.Value) error {
-	switch fieldVal.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		switch valueVal.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldVal.SetInt(valueVal.Int())
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			fieldVal.SetInt(int64(valueVal.Uint()))
-		case reflect.Float32, reflect.Float64:
-			fieldVal.SetInt(int64(valueVal.Float()))
-		default:
-			return fmt.Errorf("ctag: cannot convert %v to %v", valueVal.Type(), fieldVal.Type())
-		}
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		switch valueVal.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldVal.SetUint(uint64(valueVal.Int()))
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			fieldVal.SetUint(valueVal.Uint())
-		case reflect.Float32, reflect.Float64:
-			fieldVal.SetUint(uint64(valueVal.Float()))
-		default:
-			return fmt.Errorf("ctag: cannot convert %v to %v", valueVal.Type(), fieldVal.Type())
-		}
-	case reflect.Float32, reflect.Float64:
-		switch valueVal.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			fieldVal.SetFloat(float64(valueVal.Int()))
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			fieldVal.SetFloat(float64(valueVal.Uint()))
-		case reflect.Float32, reflect.Float64:
-			fieldVal.SetFloat(valueVal.Float())
-		default:
-			return fmt.Errorf("ctag: cannot convert %v to %v", valueVal.Type(), fieldVal.Type())
-		}
-	default:
-		return fmt.Errorf("ctag: unsupported numeric type %v", fieldVal.Type())
+// limit reports a cycle or depth limit reached during the traversal. With LimitError the
+// error is returned and ends the traversal; otherwise nil is returned and the value is not descended into.
+func (w *walker) limit(err error) error {
+	if w.limitMode == LimitError {
+		return err
 	}
 	return nil
 }
 
-func isNumeric(k reflect.Kind) bool {
-	switch k {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64:
+// skip reports whether the field described by fm with the dereferenced value fv is skipped.
+func (w *walker) skip(fm fieldMeta, fv reflect.Value) bool {
+	if fm.skip {
 		return true
 	}
-	return false
+	switch w.omitEmpty {
+	case OmitEmptyNever:
+		return false
+	case OmitEmptyAlways:
+		return fv.IsZero()
+	}
+	return fm.omitempty && fv.IsZero()
 }
 
-func setStructValue(fieldVal reflect.Value, value any) error {
-	valueVal := reflect.ValueOf(value)
+// descend reports whether a nested struct field at the given depth is descended into.
+func (w *walker) descend(depth int) bool {
+	return w.nested && (w.maxDepth == 0 || depth < w.maxDepth)
+}
 
-	if valueVal.Type().AssignableTo(fieldVal.Type()) {
-		fieldVal.Set(valueVal)
-		return nil
+// getTags appends the tags of the struct value v to out for every key marked in active.
+// A key that skips a struct field, for example with "-", stays inactive for everything
+// nested beneath that field, so that each key sees the same fields it would in its own traversal.
+// The names of the tags are qualified with the prefix for their key in prefixes, which may be nil.
+func (w *walker) getTags(v reflect.Value, path string, index []int, prefixes []string, depth int, active []bool, out []CTags) error {
+	return w.getFieldTags(v, path, index, prefixes, depth, active, out, w.newScope(v.Type(), index, active))
+}
+
+// getFieldTags appends the tags of the fields of the struct value v to out, resolving the
+// names of promoted fields within sc. Embedded structs that are flattened share the scope of their parent.
+func (w *walker) getFieldTags(v reflect.Value, path string, index []int, prefixes []string, depth int, active []bool, out []CTags, sc *scope) error {
+	if v.CanAddr() {
+		ptr := v.UnsafeAddr()
+		if !w.enter(ptr, v.Type()) {
+			return w.limit(&CycleError{Path: path, Type: v.Type()})
+		}
+		defer w.leave(ptr, v.Type())
+	}
+	if w.allocate {
+		w.types[v.Type()]++
+		defer func() { w.types[v.Type()]-- }()
 	}
 
-	if valueVal.Kind() == reflect.Map && valueVal.Type().Key().Kind() == reflect.String {
-		return setStructFromMap(fieldVal, valueVal)
+	var embedded []embeddedStruct
+	var fields []fieldMeta
+	var buf [4]*typeMeta
+	metas := buf[:0]
+	if len(w.keys) > len(buf) {
+		metas = make([]*typeMeta, 0, len(w.keys))
+	}
+	metas = metas[:len(w.keys)]
+	for k, key := range w.keys {
+		if active[k] {
+			metas[k] = cachedTypeMeta(v.Type(), key, w.fallbacks)
+			fields = metas[k].fields
+		}
 	}
 
-	return fmt.Errorf("ctag: cannot convert %T to %v", value, fieldVal.Type())
-}
+	refs := fieldRefs{path: path, index: index, fields: fields}
+	for i, f := range fields {
+		fv := v.Field(f.index)
+
+		for fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				break
+			}
+			fv = fv.Elem()
+		}
 
-func setStructFromMap(structVal reflect.Value, mapVal reflect.Value) error {
-	structType := structVal.Type()
+		var dropBuf [4]int
+		drops, live, flat := dropBuf[:0], 0, 0
+		for k := range w.keys {
+			if !active[k] {
+				continue
+			}
 
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-		fieldVal := structVal.Field(i)
+			fm := metas[k].fields[i]
+			if fm.err == nil && !w.skip(fm, fv) && !sc.hides(k, index, fm.index) {
+				live++
+				if w.flatten(fm) {
+					flat++
+					continue
+				}
+				if err := w.emit(k, fm, v, fv, &refs, i, prefixAt(prefixes, k), out); err != nil {
+					return err
+				}
+				continue
+			}
 
-		if field.PkgPath != "" || !fieldVal.CanSet() {
-			continue
+			if fm.err != nil {
+				if err := w.fail(&FieldError{Path: refs.pathAt(i), Key: w.keys[k], Err: fm.err}); err != nil {
+					return err
+				}
+			}
+			drops = append(drops, k)
 		}
 
-		jsonTag := field.Tag.Get("json")
-		if jsonTag == "-" {
+		if live == 0 {
 			continue
 		}
-
-		tagName := field.Name
-		if jsonTag != "" {
-			if parts := strings.Split(jsonTag, ","); parts[0] != "" {
-				tagName = parts[0]
+		next := active
+		if len(drops) > 0 {
+			next = slices.Clone(active)
+			for _, k := range drops {
+				next[k] = false
+			}
+		}
+		fpath, findex := refs.pathAt(i), refs.indexAt(i)
+
+		if flat > 0 && flat < live {
+			// With EmbedPromote, some keys flatten this embedded field and others treat it
+			// as a named field, so each group continues with only its own keys active.
+			var flatActive []bool
+			flatActive, next = w.splitFlatten(metas, i, next)
+			if fv.Kind() == reflect.Struct {
+				e := embeddedStruct{v: fv, path: fpath, index: findex, prefixes: w.childPrefixes(metas, i, flatActive, prefixes), active: flatActive}
+				if f.anonymous {
+					embedded = append(embedded, e)
+				} else if err := w.getFlattenedTags(e, depth, out, sc); err != nil {
+					return err
+				}
 			}
 		}
 
-		var mapValue interface{}
-		var found bool
-
-		if mapKey := reflect.ValueOf(tagName); mapVal.MapIndex(mapKey).IsValid() {
-			mapValue = mapVal.MapIndex(mapKey).Interface()
-			found = true
-		} else if tagName != field.Name {
-			if fieldKey := reflect.ValueOf(field.Name); mapVal.MapIndex(fieldKey).IsValid() {
-				mapValue = mapVal.MapIndex(fieldKey).Interface()
-				found = true
+		alloc := w.allocatable(fv)
+		if flat == live {
+			var allocated reflect.Value
+			if alloc {
+				allocated, fv = fv, allocate(fv)
+			}
+			if fv.IsValid() && fv.Kind() == reflect.Struct {
+				e := embeddedStruct{v: fv, path: fpath, index: findex, prefixes: w.childPrefixes(metas, i, next, prefixes), active: next, allocated: allocated}
+				if f.anonymous {
+					embedded = append(embedded, e)
+				} else if err := w.getFlattenedTags(e, depth, out, sc); err != nil {
+					return err
+				}
 			}
+			continue
 		}
 
-		if found {
-			if err := SetField(fieldVal.Addr().Interface(), mapValue); err != nil {
-				return fmt.Errorf("ctag: error setting field %s: %w", field.Name, err)
+		if fv.Kind() != reflect.Struct && !alloc && !(w.collections && holdsStructs(fv.Type())) {
+			continue
+		}
+		if !w.descend(depth) {
+			if w.nested {
+				if err := w.limit(&DepthError{Path: fpath, MaxDepth: w.maxDepth}); err != nil {
+					return err
+				}
 			}
+			continue
+		}
+
+		var err error
+		fprefixes := w.childPrefixes(metas, i, next, prefixes)
+		if alloc {
+			allocated := fv
+			err = w.getTags(allocate(fv), fpath, findex, fprefixes, depth+1, next, out)
+			release(allocated)
+		} else if fv.Kind() == reflect.Struct {
+			err = w.getTags(fv, fpath, findex, fprefixes, depth+1, next, out)
+		} else {
+			err = w.getCollectionTags(fv, fpath, fprefixes, depth+1, next, out)
+		}
+		if err != nil {
+			return err
 		}
 	}
 
+	for _, e := range embedded {
+		if err := w.getFlattenedTags(e, depth, out, sc); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func setSliceFromSlice(fieldVal reflect.Value, sourceSlice reflect.Value) error {
-	if sourceSlice.Len() == 0 {
-		fieldVal.Set(reflect.MakeSlice(fieldVal.Type(), 0, 0))
-		return nil
+// getFlattenedTags appends the tags of the fields of the flattened struct e to out as if they
+// were fields of its parent, within the parent's scope sc. Embedded structs are flattened after
+// the other fields of their parent, while inline fields are flattened in place, in field order.
+func (w *walker) getFlattenedTags(e embeddedStruct, depth int, out []CTags, sc *scope) error {
+	err := w.getFieldTags(e.v, e.path, e.index, e.prefixes, depth, e.active, out, sc)
+	if e.allocated.IsValid() {
+		release(e.allocated)
 	}
+	return err
+}
 
-	slice := reflect.MakeSlice(fieldVal.Type(), sourceSlice.Len(), sourceSlice.Len())
-
-	for i := 0; i < sourceSlice.Len(); i++ {
-		sourceElem := sourceSlice.Index(i).Interface()
-		targetElem := slice.Index(i)
+// allocatable reports whether fv is a settable nil pointer, possibly to further pointers,
+// to a struct that should be allocated with WithAllocate. Struct types already being
+// traversed on the current path are not allocated, so that recursive types terminate.
+func (w *walker) allocatable(fv reflect.Value) bool {
+	if !w.allocate || fv.Kind() != reflect.Ptr || !fv.IsNil() || !fv.CanSet() {
+		return false
+	}
+	t := fv.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && w.types[t] == 0
+}
 
-		if targetElem.CanAddr() {
-			if err := SetField(targetElem.Addr().Interface(), sourceElem); err != nil {
-				return fmt.Errorf("ctag: error converting slice element %d: %w", i, err)
-			}
-		} else {
-			if err := setValue(targetElem, sourceElem); err != nil {
-				return fmt.Errorf("ctag: error converting slice element %d: %w", i, err)
-			}
+// allocate fills the nil pointer p, and any nil pointers it leads to, with newly
+// allocated values and returns the struct it finally points to.
+func allocate(p reflect.Value) reflect.Value {
+	for p.Kind() == reflect.Ptr {
+		if p.IsNil() {
+			p.Set(reflect.New(p.Type().Elem()))
 		}
+		p = p.Elem()
 	}
-
-	fieldVal.Set(slice)
-	return nil
+	return p
 }
 
-func getTags(key string, v reflect.Value, p TagProcessor) (CTags, error) {
-	var embedded []reflect.Value
-	var tags CTags
-	t := v.Type()
+// release resets the pointer p, filled by allocate, to nil if the struct it leads to
+// is still the zero value, dropping an allocation that nothing was stored into.
+func release(p reflect.Value) {
+	v := p
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.IsZero() {
+		p.Set(reflect.Zero(p.Type()))
+	}
+}
 
-	for i := 0; i < v.NumField(); i++ {
-		f := t.Field(i)
-		fv := v.Field(i)
+// emit builds the tag for key index k on the field described by fm, the i-th field in refs
+// of the struct v, processes it and appends it to out. Untagged fields are only emitted when
+// WithUntagged is set. The tag's Name is qualified with prefix.
+func (w *walker) emit(k int, fm fieldMeta, v reflect.Value, fv reflect.Value, refs *fieldRefs, i int, prefix string, out []CTags) error {
+	name, ok := w.tagName(fm)
+	if !ok {
+		return nil
+	}
 
-		if f.PkgPath != "" && !f.Anonymous {
-			continue
-		}
+	path := refs.pathAt(i)
+	tag := newTag(w.keys[k], fm, fv, path, refs.indexAt(i))
+	tag.Name = prefix + name
+	name = tag.Name
+	if err := w.process(w.processorFor(w.keys[k]), v, v.Field(fm.index), &tag); err != nil {
+		return w.fail(&FieldError{Path: path, Key: w.keys[k], Name: name, Err: err})
+	}
+	out[k] = append(out[k], tag)
+	return nil
+}
 
-		for fv.Kind() == reflect.Ptr {
-			if fv.IsNil() {
-				break
-			}
-			fv = fv.Elem()
-		}
+// tagName returns the unqualified Name of the field described by fm, and false if the
+// field is untagged and untagged fields are not included.
+func (w *walker) tagName(fm fieldMeta) (string, bool) {
+	if fm.tag != "" {
+		return fm.name, true
+	}
+	if !w.untagged {
+		return "", false
+	}
+	return w.untaggedName(fm.field.Name), true
+}
 
-		tagStr := f.Tag.Get(key)
-		if tagStr == "-" || (strings.Contains(tagStr, "omitempty") && fv.IsZero()) {
-			continue
-		}
+// untaggedName returns the Name of an untagged field with the given Go name.
+func (w *walker) untaggedName(fieldName string) string {
+	if w.naming == nil {
+		return fieldName
+	}
+	return w.naming(fieldName)
+}
 
-		if f.Anonymous {
-			if fv.IsValid() && fv.Kind() == reflect.Struct {
-				embedded = append(embedded, fv)
-			}
-			continue
-		}
+// processorFor returns the TagProcessor configured for key, falling back to the
+// processor set with WithProcessor.
+func (w *walker) processorFor(key string) TagProcessor {
+	if p, ok := w.processors[key]; ok {
+		return p
+	}
+	return w.processor
+}
 
-		if tagStr != "" {
-			tag := parse(key, tagStr, fv)
-			if p != nil {
-				originalField := v.Field(i)
-				if originalField.CanSet() {
-					if err := p.Process(originalField.Addr().Interface(), &tag); err != nil {
-						return nil, fmt.Errorf("error processing field: %w", err)
-					}
-					tag.Field = originalField.Interface()
-				} else {
-					if err := p.Process(tag.Field, &tag); err != nil {
-						return nil, fmt.Errorf("error processing field: %w", err)
-					}
-				}
-			}
-			tags = append(tags, tag)
-		}
+// structProcessor is implemented by the TagProcessors of this package that also need
+// the struct declaring the field, such as the processor validating cross-field rules.
+type structProcessor interface {
+	processIn(parent reflect.Value, field any, tag *CTag) error
+}
 
-		if fv.Kind() == reflect.Struct && !f.Anonymous {
-			if nestedTags, err := getTags(key, fv, p); err != nil {
-				return nil, err
-			} else {
-				tags = append(tags, nestedTags...)
-			}
-		}
+// process applies the processor p, if any, to the field fv of the struct parent described
+// by tag. Settable fields are passed to the processor as a pointer and the tag's Field is
+// refreshed with the processed value.
+func (w *walker) process(p TagProcessor, parent reflect.Value, fv reflect.Value, tag *CTag) error {
+	if p == nil {
+		return nil
+	}
+	field := tag.Field
+	if fv.CanSet() {
+		field = fv.Addr().Interface()
 	}
 
-	for _, f := range embedded {
-		if etags, err := getTags(key, f, p); err != nil {
-			return nil, err
-		} else {
-			tags = append(tags, etags...)
-		}
+	var err error
+	if sp, ok := p.(structProcessor); ok {
+		err = sp.processIn(parent, field, tag)
+	} else {
+		err = p.Process(field, tag)
+	}
+	if err == nil && fv.CanSet() {
+		tag.Field = fv.Interface()
 	}
-	return tags, nil
+	return err
 }
 
-func parse(key string, tagStr string, fv reflect.Value) CTag {
+// embeddedStruct is an embedded or inline struct value flattened into its parent, along
+// with its location and the keys still active for it.
+type embeddedStruct struct {
+	v         reflect.Value
+	path      string
+	index     []int
+	prefixes  []string
+	active    []bool
+	allocated reflect.Value // allocated is the pointer filled by allocate to reach v, if any.
+}
+
+// newTag builds a CTag for the field described by fm holding the value fv.
+// The cached options are copied so that processors may modify them freely.
+func newTag(key string, fm fieldMeta, fv reflect.Value, path string, index []int) CTag {
 	v := reflect.Indirect(fv)
-	tag := CTag{Key: key}
+	tag := CTag{
+		Key:         key,
+		Name:        fm.name,
+		Options:     slices.Clone(fm.options),
+		MatchedKey:  fm.matched,
+		FieldName:   fm.field.Name,
+		Path:        path,
+		Index:       index,
+		StructField: fm.field,
+	}
 
 	if v.IsValid() {
 		tag.Field = v.Interface()
 	}
+	return tag
+}
+
+// fieldRefs builds the Path and Index of the fields of one struct value on demand. The
+// paths of all the fields share one string and their indexes share one backing array,
+// so a struct costs at most two allocations however many of its fields are emitted.
+type fieldRefs struct {
+	path    string      // path is the Path of the struct itself.
+	index   []int       // index is the Index of the struct itself.
+	fields  []fieldMeta // fields are the visible fields of the struct.
+	paths   string      // paths holds the paths of all the fields, built on first use.
+	indexes []int       // indexes holds the indexes of all the fields, built on first use.
+}
+
+// pathAt returns the dotted Path of the i-th field.
+func (r *fieldRefs) pathAt(i int) string {
+	f := r.fields[i]
+	if r.path == "" {
+		return f.field.Name
+	}
+	stride := len(r.path) + 1
+	if r.paths == "" {
+		var sb strings.Builder
+		sb.Grow(len(r.fields)*stride + r.fields[len(r.fields)-1].nameEnd)
+		for _, f := range r.fields {
+			sb.WriteString(r.path)
+			sb.WriteByte('.')
+			sb.WriteString(f.field.Name)
+		}
+		r.paths = sb.String()
+	}
+	end := (i+1)*stride + f.nameEnd
+	return r.paths[end-stride-len(f.field.Name) : end]
+}
 
-	parts := strings.SplitN(tagStr, ",", 2)
-	tag.Name = parts[0]
-	if len(parts) > 1 {
-		tag.Options = strings.Split(parts[1], ",")
+// indexAt returns the Index of the i-th field. Its capacity is capped so that appending
+// to it never overwrites the index of another field.
+func (r *fieldRefs) indexAt(i int) []int {
+	n := len(r.index) + 1
+	if r.indexes == nil {
+		r.indexes = make([]int, len(r.fields)*n)
+		for j, f := range r.fields {
+			copy(r.indexes[j*n:], r.index)
+			r.indexes[j*n+n-1] = f.index
+		}
 	}
-	return tag
+	return r.indexes[i*n : (i+1)*n : (i+1)*n]
+}
+
+// appendIndex returns a new index sequence with i appended, leaving index untouched.
+func appendIndex(index []int, i int) []int {
+	out := make([]int, len(index)+1)
+	copy(out, index)
+	out[len(index)] = i
+	return out
 }
@@ -1,8 +1,10 @@
 package ctag
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -378,6 +380,232 @@ func TestGetTagsAndProcess(t *testing.T) {
 	}
 }
 
+func TestGetTagsAndProcessAll(t *testing.T) {
+	type Address struct {
+		Zip  string `form:"zip"`
+		City string `form:"city"`
+	}
+	input := struct {
+		Name    string  `form:"name"`
+		Age     int     `form:"age"`
+		Email   string  `form:"email"`
+		Address Address `form:"address"`
+		Desc    string  `form:"desc,text='a'b"`
+	}{Name: "John"}
+
+	errAge := errors.New("age out of range")
+	errZip := errors.New("bad zip")
+	p := &failingProcessor{fail: map[string]error{"age": errAge, "zip": errZip}}
+
+	tags, err := GetTagsAndProcessAll("form", &input, p)
+
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	assert.Equal(t, []string{"name", "email", "address", "city"}, names)
+
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.Len(t, fieldErrs, 3)
+	assert.Equal(t, "Age", fieldErrs[0].Path)
+	assert.Equal(t, "Address.Zip", fieldErrs[1].Path)
+	assert.Equal(t, "Desc", fieldErrs[2].Path)
+
+	assert.ErrorIs(t, err, errAge)
+	assert.ErrorIs(t, err, errZip)
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "age", fieldErr.Name)
+
+	assert.Equal(t, errors.Join(fieldErrs[0], fieldErrs[1], fieldErrs[2]).Error(), err.Error())
+}
+
+func TestGetTagsAndProcessAllNoErrors(t *testing.T) {
+	input := struct {
+		ID int `query:"id"`
+	}{ID: 1}
+
+	tags, err := GetTagsAndProcessAll("query", &input, &testProcessor{})
+	assertTags(t, CTags{{Key: "query", Name: "processed_id", Field: 1}}, tags, err)
+	assert.Nil(t, err)
+
+	_, err = GetTagsAndProcessAll("query", 42, nil)
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+type keyProcessor struct {
+	key    string
+	fields []string
+}
+
+func (p *keyProcessor) Process(field any, tag *CTag) error {
+	p.fields = append(p.fields, tag.Path)
+	if s, ok := field.(*string); ok {
+		*s = p.key + "_" + tag.Name
+	}
+	return nil
+}
+
+func TestGetMulti(t *testing.T) {
+	type Filter struct {
+		Status string `query:"status"`
+		Hidden string `header:"X-Hidden"`
+	}
+	type Request struct {
+		ID     string `path:"id"`
+		Limit  string `query:"limit"`
+		Token  string `header:"Authorization" query:"token,omitempty"`
+		Filter Filter `query:"filter" header:"-"`
+		Body   string `body:"body"`
+	}
+
+	var request Request
+	pathProcessor := &keyProcessor{key: "path"}
+	queryProcessor := &keyProcessor{key: "query"}
+	fallback := &keyProcessor{key: "other"}
+
+	tags, err := GetMulti([]string{"path", "query", "header", "path"}, &request,
+		WithProcessor(fallback),
+		WithProcessors(map[string]TagProcessor{
+			"path":  pathProcessor,
+			"query": queryProcessor,
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 3)
+
+	assert.Equal(t, []string{"id"}, tagNames(tags["path"]))
+	assert.Equal(t, []string{"limit", "filter", "status"}, tagNames(tags["query"]))
+	assert.Equal(t, []string{"Authorization"}, tagNames(tags["header"]))
+
+	assert.Equal(t, []string{"ID"}, pathProcessor.fields)
+	assert.Equal(t, []string{"Limit", "Filter", "Filter.Status"}, queryProcessor.fields)
+	assert.Equal(t, []string{"Token"}, fallback.fields)
+
+	assert.Equal(t, "path_id", request.ID)
+	assert.Equal(t, "query_limit", request.Limit)
+	assert.Equal(t, "other_Authorization", request.Token)
+	assert.Equal(t, "query_status", request.Filter.Status)
+	assert.Equal(t, "", request.Filter.Hidden)
+	assert.Equal(t, "", request.Body)
+}
+
+func TestGetMultiMatchesGet(t *testing.T) {
+	input := optionsRoot{
+		ID:     1,
+		Branch: optionsBranch{Leaf: optionsLeaf{Value: "v"}, Count: 2},
+	}
+	keys := []string{"query", "json", "missing"}
+
+	grouped, err := GetMulti(keys, input, WithUntagged(true))
+	assert.NoError(t, err)
+
+	for _, key := range keys {
+		tags, err := Get(key, input, WithUntagged(true))
+		assert.NoError(t, err)
+		assert.Equal(t, tags, grouped[key], key)
+	}
+}
+
+func TestGetMultiErrors(t *testing.T) {
+	input := struct {
+		Name string `query:"name" header:"X-Name"`
+		Age  int    `query:"age"`
+	}{}
+
+	errName := errors.New("bad name")
+	errAge := errors.New("bad age")
+	p := &failingProcessor{fail: map[string]error{"X-Name": errName, "age": errAge}}
+
+	tags, err := GetMulti([]string{"query", "header"}, &input, WithProcessor(p), WithErrorMode(CollectErrors))
+	assert.Equal(t, []string{"name"}, tagNames(tags["query"]))
+	assert.Empty(t, tags["header"])
+
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.Len(t, fieldErrs, 2)
+	assert.Equal(t, "header", fieldErrs[0].Key)
+	assert.Equal(t, "query", fieldErrs[1].Key)
+
+	_, err = GetMulti([]string{"query"}, "not a struct")
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestGetTagsFieldMetadata(t *testing.T) {
+	type Address struct {
+		Name string `query:"name"`
+	}
+	type Audit struct {
+		Version int `query:"version"`
+	}
+	type User struct {
+		Audit
+		Name    string   `query:"name"`
+		Address Address  `query:"address"`
+		Backup  *Address `query:"backup"`
+	}
+
+	input := User{
+		Audit:   Audit{Version: 3},
+		Name:    "John",
+		Address: Address{Name: "Home"},
+		Backup:  &Address{Name: "Work"},
+	}
+
+	tags, err := GetTags("query", input)
+	assert.NoError(t, err)
+
+	expected := []struct {
+		name      string
+		fieldName string
+		path      string
+		index     []int
+	}{
+		{name: "name", fieldName: "Name", path: "Name", index: []int{1}},
+		{name: "address", fieldName: "Address", path: "Address", index: []int{2}},
+		{name: "name", fieldName: "Name", path: "Address.Name", index: []int{2, 0}},
+		{name: "backup", fieldName: "Backup", path: "Backup", index: []int{3}},
+		{name: "name", fieldName: "Name", path: "Backup.Name", index: []int{3, 0}},
+		{name: "version", fieldName: "Version", path: "Audit.Version", index: []int{0, 0}},
+	}
+
+	assert.Len(t, tags, len(expected))
+	for i, e := range expected {
+		assert.Equal(t, e.name, tags[i].Name)
+		assert.Equal(t, e.fieldName, tags[i].FieldName)
+		assert.Equal(t, e.path, tags[i].Path)
+		assert.Equal(t, e.index, tags[i].Index)
+		assert.Equal(t, e.fieldName, tags[i].StructField.Name)
+		assert.Equal(t, reflect.StructTag(`query:"`+e.name+`"`), tags[i].StructField.Tag)
+	}
+
+	rv := reflect.ValueOf(input)
+	assert.Equal(t, "Home", rv.FieldByIndex(tags[2].Index).Interface())
+	assert.Equal(t, 3, rv.FieldByIndex(tags[5].Index).Interface())
+}
+
+func TestGetTagsIndexIsolated(t *testing.T) {
+	type Address struct {
+		Street string `query:"street"`
+		City   string `query:"city"`
+	}
+	type User struct {
+		Home Address `query:"home"`
+	}
+
+	tags, err := GetTags("query", User{})
+	assert.NoError(t, err)
+	assert.Len(t, tags, 3)
+	assert.Equal(t, "Home.Street", tags[1].Path)
+	assert.Equal(t, "Home.City", tags[2].Path)
+
+	_ = append(tags[1].Index, 9)
+	tags[1].Index[1] = 7
+	assert.Equal(t, []int{0, 1}, tags[2].Index)
+}
+
 func TestFilter(t *testing.T) {
 	tags := CTags{
 		{Key: "body", Name: "json", Field: "1,2,3,4"},
@@ -593,6 +821,122 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestOption(t *testing.T) {
+	tag := &CTag{
+		Key:     "query",
+		Name:    "limit",
+		Options: []string{"omitempty", "default=10", "layout=2006-01-02", "sep=|", "empty=", "default=20"},
+	}
+
+	tests := []struct {
+		name          string
+		option        string
+		expectedValue string
+		expectedOk    bool
+	}{
+		{name: "bare flag", option: "omitempty", expectedValue: "", expectedOk: true},
+		{name: "key value", option: "layout", expectedValue: "2006-01-02", expectedOk: true},
+		{name: "separator value", option: "sep", expectedValue: "|", expectedOk: true},
+		{name: "empty value", option: "empty", expectedValue: "", expectedOk: true},
+		{name: "first match wins", option: "default", expectedValue: "10", expectedOk: true},
+		{name: "missing", option: "min", expectedValue: "", expectedOk: false},
+		{name: "prefix is not a match", option: "omit", expectedValue: "", expectedOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := tag.Option(tt.option)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedOk, tag.HasOption(tt.option))
+		})
+	}
+}
+
+func TestOptionTyped(t *testing.T) {
+	tag := &CTag{
+		Key:     "env",
+		Name:    "TIMEOUT",
+		Options: []string{"required", "min=1", "ratio=0.5", "trim=false", "timeout=30s", "bad=abc", "flag"},
+	}
+
+	t.Run("int", func(t *testing.T) {
+		v, ok, err := tag.OptionInt("min")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		_, ok, err = tag.OptionInt("max")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, ok, err = tag.OptionInt("bad")
+		assert.True(t, ok)
+		assert.ErrorContains(t, err, "cannot parse \"abc\" as int")
+
+		_, ok, err = tag.OptionInt("flag")
+		assert.True(t, ok)
+		assert.ErrorContains(t, err, "has no value")
+	})
+
+	t.Run("float", func(t *testing.T) {
+		v, ok, err := tag.OptionFloat("ratio")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 0.5, v)
+
+		_, _, err = tag.OptionFloat("bad")
+		assert.ErrorContains(t, err, "as float")
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		v, ok, err := tag.OptionBool("required")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, v)
+
+		v, ok, err = tag.OptionBool("trim")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, v)
+
+		v, ok, err = tag.OptionBool("missing")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.False(t, v)
+
+		_, _, err = tag.OptionBool("bad")
+		assert.ErrorContains(t, err, "as bool")
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		v, ok, err := tag.OptionDuration("timeout")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 30*time.Second, v)
+
+		_, _, err = tag.OptionDuration("min")
+		assert.ErrorContains(t, err, "as duration")
+	})
+}
+
+func TestOptionFromQuotedTag(t *testing.T) {
+	input := struct {
+		Tags []string `query:"tags,sep=',',default='a,b'"`
+	}{}
+
+	tags, err := GetTags("query", input)
+	assert.NoError(t, err)
+
+	sep, ok := tags[0].Option("sep")
+	assert.True(t, ok)
+	assert.Equal(t, ",", sep)
+
+	def, ok := tags[0].Option("default")
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", def)
+}
+
 type testProcessor struct{}
 
 func (p *testProcessor) Process(field any, tag *CTag) error {
@@ -652,9 +996,16 @@ func TestSetField(t *testing.T) {
 			expected: 42,
 		},
 		{
-			name:     "float to int",
+			name:        "float to int",
+			field:       func() any { var i int; return &i }(),
+			value:       42.7,
+			expectError: true,
+			errorMsg:    "42.7 has a fractional part",
+		},
+		{
+			name:     "whole float to int",
 			field:    func() any { var i int; return &i }(),
-			value:    42.7,
+			value:    42.0,
 			expected: 42,
 		},
 		{
@@ -816,8 +1167,8 @@ func TestSetField(t *testing.T) {
 		},
 		{
 			name:        "incompatible map types",
-			field:       func() any { var m map[string]string; return &m }(),
-			value:       map[int]int{1: 2},
+			field:       func() any { var m map[string]int; return &m }(),
+			value:       map[string]bool{"a": true},
 			expectError: true,
 			errorMsg:    "cannot convert",
 		},
@@ -930,7 +1281,7 @@ func TestSetFieldNumericConversions(t *testing.T) {
 		{
 			name:     "float64 to int",
 			field:    func() any { var i int; return &i }(),
-			value:    42.9,
+			value:    42.0,
 			expected: 42,
 		},
 
@@ -944,7 +1295,7 @@ func TestSetFieldNumericConversions(t *testing.T) {
 		{
 			name:     "float to uint",
 			field:    func() any { var u uint; return &u }(),
-			value:    42.9,
+			value:    42.0,
 			expected: uint(42),
 		},
 
@@ -0,0 +1,109 @@
+package ctag
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DefaultKey is the tag key whose whole value is the default of a field, as in `default:"8080"`.
+const DefaultKey = "default"
+
+// ApplyDefaults sets the zero-valued fields of the struct data points to from the defaults in
+// their tags for key. With DefaultKey, the whole tag value is the default, commas included, so
+// `default:"a,b"` sets a []string to [a b]. With any other key, the default is the value of the
+// default= option, which may be single-quoted to contain commas, as in `env:"HOSTS,default='a,b'"`.
+//
+// Defaults are converted with SetField, configured by the options set with WithSetOptions and then
+// by the options of the tag itself, as with WithTag. The struct is traversed as by Get, configured
+// by the options set with WithGetOptions. Nested structs are descended into, nil pointers to structs are
+// allocated and kept only if a default was set inside them, and structs held in slices, arrays
+// and maps have their own defaults applied. A field holding a non-zero value is left alone, as is
+// a pointer to a zero value, which records an explicit zero, unless WithOverwriteZero is set.
+//
+// Parameters:
+//
+//	key  - the tag key holding the defaults, such as DefaultKey or "env"
+//	data - a pointer to the struct whose fields should be defaulted
+//	opts - options such as WithOverwriteZero, WithSetOptions or WithGetOptions
+//
+// Returns:
+//
+//	ErrNotPointer if data is not a pointer, ErrNotStruct if it does not point to a struct,
+//	or a *FieldError wrapping the error of the first default that cannot be set.
+//
+// Example usage:
+//
+//	type Config struct {
+//	    Port    int           `env:"PORT,default=8080"`
+//	    Timeout time.Duration `env:"TIMEOUT,default=30s"`
+//	    Hosts   []string      `env:"HOSTS,default='a.example.com,b.example.com'"`
+//	}
+//
+//	config := Config{Port: 9090}
+//	err := ApplyDefaults("env", &config)
+//	// config.Port == 9090, config.Timeout == 30 * time.Second
+func ApplyDefaults(key string, data any, opts ...DefaultsOption) error {
+	if reflect.ValueOf(data).Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T", ErrNotPointer, data)
+	}
+	o := newDefaultsOptions(opts)
+	getOpts := append([]Option{
+		WithOmitEmpty(OmitEmptyNever),
+		WithAllocate(true),
+		WithCollections(true),
+	}, o.get...)
+	getOpts = append(getOpts, WithProcessor(&defaultsProcessor{opts: o}), WithProcessors(nil))
+	_, err := Get(key, data, getOpts...)
+	return err
+}
+
+// defaultsProcessor is the TagProcessor of ApplyDefaults, setting each unset field to its default.
+type defaultsProcessor struct {
+	opts defaultsOptions // opts configure the conversion of every default.
+}
+
+// Process sets the field to the default in tag, unless the field is already set.
+func (p *defaultsProcessor) Process(field any, tag *CTag) error {
+	value, ok := defaultValue(tag)
+	if !ok {
+		return nil
+	}
+
+	fieldVal := reflect.ValueOf(field)
+	if fieldVal.Kind() != reflect.Ptr || fieldVal.IsNil() {
+		return fmt.Errorf("%w, got %T", ErrNotPointer, field)
+	}
+
+	if !p.unset(fieldVal.Elem()) {
+		return nil
+	}
+	opts := append(append([]SetOption(nil), p.opts.set...), WithTag(tag))
+	return newSetter(opts).setValue(fieldVal.Elem(), value)
+}
+
+// defaultValue returns the default in tag: its whole value if it was read from DefaultKey, as
+// the key or a fallback key, or the value of its default= option otherwise. It returns false if
+// the tag has no default or the default is empty.
+func defaultValue(tag *CTag) (string, bool) {
+	if tag.Key == DefaultKey || tag.MatchedKey == DefaultKey {
+		value := tag.StructField.Tag.Get(DefaultKey)
+		return value, value != ""
+	}
+	value, ok := tag.Option("default")
+	return value, ok && value != ""
+}
+
+// unset reports whether fieldVal should receive its default: whether it is the zero value or,
+// with WithOverwriteZero, a pointer to one.
+func (p *defaultsProcessor) unset(fieldVal reflect.Value) bool {
+	if fieldVal.IsZero() {
+		return true
+	}
+	if !p.opts.overwrite {
+		return false
+	}
+	for fieldVal.Kind() == reflect.Ptr && !fieldVal.IsNil() {
+		fieldVal = fieldVal.Elem()
+	}
+	return fieldVal.IsZero()
+}
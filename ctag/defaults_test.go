@@ -0,0 +1,193 @@
+package ctag
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type defaultsDatabase struct {
+	Host string `default:"localhost" env:"DB_HOST,default=localhost"`
+	Port int    `default:"5432" env:"DB_PORT,default=5432"`
+}
+
+type defaultsWorker struct {
+	Name     string `default:"worker"`
+	Replicas int    `default:"1"`
+}
+
+type defaultsConfig struct {
+	Port     int               `default:"8080" env:"PORT,default=8080"`
+	Timeout  time.Duration     `default:"30s" env:"TIMEOUT,omitempty,default=30s"`
+	Hosts    []string          `default:"a.example.com,b.example.com" env:"HOSTS,default='a.example.com,b.example.com'"`
+	Debug    bool              `env:"DEBUG"`
+	Retries  *int              `default:"3" env:"RETRIES,default=3"`
+	Started  time.Time         `default:"2024-01-31T00:00:00Z" env:"STARTED,layout=DateOnly,default=2024-01-31"`
+	Database defaultsDatabase  `env:"DB"`
+	Cache    *defaultsDatabase `env:"CACHE"`
+	Workers  []defaultsWorker
+}
+
+func TestApplyDefaults(t *testing.T) {
+	started := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		key      string
+		config   defaultsConfig
+		opts     []DefaultsOption
+		expected func(c *defaultsConfig)
+	}{
+		{
+			name: "env key",
+			key:  "env",
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, 8080, c.Port)
+				assert.Equal(t, 30*time.Second, c.Timeout)
+				assert.Equal(t, []string{"a.example.com", "b.example.com"}, c.Hosts)
+				assert.False(t, c.Debug)
+				assert.Equal(t, ptr(3), c.Retries)
+				assert.Equal(t, defaultsDatabase{Host: "localhost", Port: 5432}, c.Database)
+				assert.Equal(t, &defaultsDatabase{Host: "localhost", Port: 5432}, c.Cache)
+				assert.Equal(t, started, c.Started)
+			},
+		},
+		{
+			name: "default key with whole tag value",
+			key:  DefaultKey,
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, 8080, c.Port)
+				assert.Equal(t, []string{"a.example.com", "b.example.com"}, c.Hosts)
+				assert.Equal(t, ptr(3), c.Retries)
+				assert.Equal(t, defaultsDatabase{Host: "localhost", Port: 5432}, c.Database)
+				assert.Equal(t, started, c.Started)
+			},
+		},
+		{
+			name:   "set values are kept",
+			key:    "env",
+			config: defaultsConfig{Port: 9090, Hosts: []string{"c"}, Database: defaultsDatabase{Port: 6543}},
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, 9090, c.Port)
+				assert.Equal(t, []string{"c"}, c.Hosts)
+				assert.Equal(t, defaultsDatabase{Host: "localhost", Port: 6543}, c.Database)
+			},
+		},
+		{
+			name:   "explicit zero pointer is kept",
+			key:    "env",
+			config: defaultsConfig{Retries: new(int)},
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, ptr(0), c.Retries)
+			},
+		},
+		{
+			name:   "explicit zero pointer is overwritten",
+			key:    "env",
+			config: defaultsConfig{Retries: new(int)},
+			opts:   []DefaultsOption{WithOverwriteZero(true)},
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, ptr(3), c.Retries)
+			},
+		},
+		{
+			name:   "existing pointer struct is defaulted",
+			key:    "env",
+			config: defaultsConfig{Cache: &defaultsDatabase{Host: "cache"}},
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, &defaultsDatabase{Host: "cache", Port: 5432}, c.Cache)
+			},
+		},
+		{
+			name:   "slice elements are defaulted",
+			key:    DefaultKey,
+			config: defaultsConfig{Workers: []defaultsWorker{{Name: "a"}, {Replicas: 3}}},
+			expected: func(c *defaultsConfig) {
+				assert.Equal(t, []defaultsWorker{{Name: "a", Replicas: 1}, {Name: "worker", Replicas: 3}}, c.Workers)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			err := ApplyDefaults(tt.key, &config, tt.opts...)
+			assert.NoError(t, err)
+			tt.expected(&config)
+		})
+	}
+}
+
+func TestApplyDefaultsUnusedPointerIsReleased(t *testing.T) {
+	type Config struct {
+		Name  string `default:"app"`
+		Cache *defaultsWorker
+		Other *struct {
+			Value string `env:"VALUE"`
+		}
+	}
+
+	var config Config
+	assert.NoError(t, ApplyDefaults(DefaultKey, &config))
+	assert.Equal(t, "app", config.Name)
+	assert.Equal(t, &defaultsWorker{Name: "worker", Replicas: 1}, config.Cache)
+	assert.Nil(t, config.Other)
+}
+
+func TestApplyDefaultsWithOptions(t *testing.T) {
+	type Config struct {
+		Limit int      `default:"0x10"`
+		Tags  []string `env:"TAGS,sep='|',default='a|b'"`
+	}
+
+	var config Config
+	assert.NoError(t, ApplyDefaults(DefaultKey, &config, WithSetOptions(WithParseMode(ParseExtended))))
+	assert.Equal(t, 16, config.Limit)
+
+	assert.NoError(t, ApplyDefaults("env", &config))
+	assert.Equal(t, []string{"a", "b"}, config.Tags)
+}
+
+func TestApplyDefaultsWithGetOptions(t *testing.T) {
+	type Inner struct {
+		Level string `default:"info"`
+	}
+	type Config struct {
+		Port  int    `env:"PORT,default=8080"`
+		Host  string `default:"localhost"`
+		Inner Inner
+	}
+
+	var config Config
+	opts := WithGetOptions(WithFallbackKeys(DefaultKey), WithNested(false))
+	assert.NoError(t, ApplyDefaults("env", &config, opts))
+	assert.Equal(t, Config{Port: 8080, Host: "localhost"}, config)
+
+	config = Config{}
+	p := &failingProcessor{fail: map[string]error{"PORT": errInvalid}}
+	opts = WithGetOptions(WithProcessor(p), WithFallbackKeys(DefaultKey))
+	assert.NoError(t, ApplyDefaults("env", &config, opts))
+	assert.Equal(t, Config{Port: 8080, Host: "localhost", Inner: Inner{Level: "info"}}, config)
+}
+
+func TestApplyDefaultsErrors(t *testing.T) {
+	type Config struct {
+		Port int `env:"PORT,default=http"`
+	}
+
+	var config Config
+	err := ApplyDefaults("env", &config)
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Port", fieldErr.Path)
+	assert.ErrorContains(t, err, `ctag: cannot parse "http" as int`)
+
+	err = ApplyDefaults("env", config)
+	assert.ErrorIs(t, err, ErrNotPointer)
+
+	n := 1
+	err = ApplyDefaults("env", &n)
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
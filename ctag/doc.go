@@ -31,4 +31,20 @@
 //	}
 //
 // tags, _ := ctag.GetTagsAndProcess("body", request, &Processor{})
+//
+// Get accepts functional options for finer control over the traversal:
+//
+//	tags, _ := ctag.Get("body", &request,
+//	    ctag.WithProcessor(&Processor{}),
+//	    ctag.WithErrorMode(ctag.CollectErrors),
+//	)
+//
+// Validate checks fields against the rules of their validate tags and reports every violation:
+//
+//	type SignUp struct {
+//	    Password string `validate:"required,min=12"`
+//	    Confirm  string `validate:"eqfield=Password"`
+//	}
+//
+//	err := ctag.Validate(&signUp)
 package ctag
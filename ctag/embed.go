@@ -0,0 +1,204 @@
+package ctag
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// scope is the struct whose namespace embedded fields are promoted into with EmbedPromote.
+// Its fields, and the fields promoted from the structs it embeds, share one set of names.
+type scope struct {
+	base   int                   // base is the length of the Index of the struct, which is dropped to make field indexes relative.
+	hidden []map[string]struct{} // hidden holds, for each key, the relative indexes of the fields shadowed in the namespace.
+}
+
+// hides reports whether the field i of the struct at index, within the namespace of s,
+// is shadowed for key index k. A nil scope hides nothing.
+func (s *scope) hides(k int, index []int, i int) bool {
+	if s == nil || s.hidden[k] == nil {
+		return false
+	}
+	_, ok := s.hidden[k][indexKey(index[s.base:], i)]
+	return ok
+}
+
+// promoteKey identifies the shadowed fields computed for a struct type and key index.
+type promoteKey struct {
+	t reflect.Type
+	k int
+}
+
+// newScope returns the scope for the namespace of the struct type t whose fields are
+// at the given index, or nil if embedded fields are not promoted.
+func (w *walker) newScope(t reflect.Type, index []int, active []bool) *scope {
+	if w.embedMode != EmbedPromote {
+		return nil
+	}
+	s := &scope{base: len(index), hidden: make([]map[string]struct{}, len(w.keys))}
+	for k := range w.keys {
+		if !active[k] {
+			continue
+		}
+		pk := promoteKey{t: t, k: k}
+		hidden, ok := w.promoted[pk]
+		if !ok {
+			hidden = w.shadowed(t, k)
+			if w.promoted == nil {
+				w.promoted = make(map[promoteKey]map[string]struct{})
+			}
+			w.promoted[pk] = hidden
+		}
+		s.hidden[k] = hidden
+	}
+	return s
+}
+
+// promotedField is a field that takes part in the name resolution of a namespace.
+type promotedField struct {
+	key    string // key is the indexKey of the field relative to the namespace struct.
+	name   string
+	depth  int // depth is the number of embedded structs the field is promoted through.
+	tagged bool
+}
+
+// shadowed returns the relative indexes of the fields of struct type t, and of the structs
+// it embeds, that are hidden for key index k by the rules Go and encoding/json apply to
+// promoted fields: of the fields sharing a name, those promoted through the fewest
+// embedded structs win, and among those a single tagged field wins over untagged ones.
+// If no single field wins, every field with the name is hidden.
+//
+// The result depends only on types, so a field hidden by one that is empty or nil in a
+// particular value stays hidden.
+func (w *walker) shadowed(t reflect.Type, k int) map[string]struct{} {
+	type level struct {
+		t      reflect.Type
+		index  []int
+		prefix string
+	}
+
+	var fields []promotedField
+	visited := map[reflect.Type]bool{}
+	current := []level{{t: t}}
+	for depth := 0; len(current) > 0; depth++ {
+		var next []level
+		for _, l := range current {
+			visited[l.t] = true
+		}
+		for _, l := range current {
+			for _, fm := range cachedTypeMeta(l.t, w.keys[k], w.fallbacks).fields {
+				if fm.err != nil || fm.skip {
+					continue
+				}
+				name, ok := w.tagName(fm)
+				if w.flatten(fm) {
+					ft := derefType(fm.field.Type)
+					if ft.Kind() == reflect.Struct && !visited[ft] {
+						prefix := l.prefix
+						if ok && name != "" && fm.prefix {
+							prefix = w.join(prefix, name)
+						}
+						next = append(next, level{t: ft, index: appendIndex(l.index, fm.index), prefix: prefix})
+					}
+					continue
+				}
+				if !ok || name == "" {
+					continue
+				}
+				fields = append(fields, promotedField{key: indexKey(l.index, fm.index), name: l.prefix + name, depth: depth, tagged: fm.tag != ""})
+			}
+		}
+		current = next
+	}
+
+	sort.SliceStable(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if fields[i].depth != fields[j].depth {
+			return fields[i].depth < fields[j].depth
+		}
+		return fields[i].tagged && !fields[j].tagged
+	})
+
+	hidden := map[string]struct{}{}
+	for start := 0; start < len(fields); {
+		end := start + 1
+		for end < len(fields) && fields[end].name == fields[start].name {
+			end++
+		}
+		winner := dominant(fields[start:end])
+		for i := start; i < end; i++ {
+			if winner < 0 || i != start+winner {
+				hidden[fields[i].key] = struct{}{}
+			}
+		}
+		start = end
+	}
+	return hidden
+}
+
+// dominant returns the position of the field that wins among fields sharing a name,
+// sorted by depth with tagged fields first, or -1 if the name is ambiguous.
+func dominant(fields []promotedField) int {
+	if len(fields) == 1 {
+		return 0
+	}
+	first, second := fields[0], fields[1]
+	if first.depth < second.depth || (first.tagged && !second.tagged) {
+		return 0
+	}
+	return -1
+}
+
+// flatten reports whether the field described by fm is flattened into its parent.
+// Every embedded field is flattened by default, as are struct fields tagged "inline" when
+// names are qualified with WithQualifiedNames. With EmbedPromote, an exported embedded field
+// that is not inline is treated like a named field if its tag gives it a name or it is not a struct.
+func (w *walker) flatten(fm fieldMeta) bool {
+	if w.qualify && fm.inline && derefType(fm.field.Type).Kind() == reflect.Struct {
+		return true
+	}
+	if !fm.anonymous {
+		return false
+	}
+	if w.embedMode != EmbedPromote || !fm.field.IsExported() {
+		return true
+	}
+	return fm.name == "" && derefType(fm.field.Type).Kind() == reflect.Struct
+}
+
+// derefType returns t with any pointer indirections removed.
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// indexKey encodes the index sequence formed by index followed by i as a map key.
+func indexKey(index []int, i int) string {
+	b := make([]byte, 0, 4*(len(index)+1))
+	for _, x := range index {
+		b = strconv.AppendInt(b, int64(x), 10)
+		b = append(b, '.')
+	}
+	return string(strconv.AppendInt(b, int64(i), 10))
+}
+
+// splitFlatten splits the keys marked in active for field i between those that flatten
+// the embedded field and those that treat it as a named field.
+func (w *walker) splitFlatten(metas []*typeMeta, i int, active []bool) (flat []bool, named []bool) {
+	flat, named = make([]bool, len(active)), make([]bool, len(active))
+	for k := range active {
+		if !active[k] {
+			continue
+		}
+		if w.flatten(metas[k].fields[i]) {
+			flat[k] = true
+		} else {
+			named[k] = true
+		}
+	}
+	return flat, named
+}
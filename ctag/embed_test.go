@@ -0,0 +1,195 @@
+package ctag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEmbedMode(t *testing.T) {
+	type Audit struct {
+		Created string `json:"created"`
+		Name    string `json:"name"`
+	}
+
+	type Base struct {
+		Audit
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	type Other struct {
+		ID    int    `json:"id"`
+		Email string `json:"email"`
+	}
+
+	type Tagged struct {
+		Email string `json:"email"`
+	}
+
+	type Label string
+
+	type User struct {
+		Base
+		*Other
+		Tagged `json:"contact"`
+		Label  `json:"label"`
+		Name   string `json:"name"`
+	}
+
+	input := User{
+		Base:   Base{Audit: Audit{Created: "today", Name: "audit"}, ID: 1, Name: "base"},
+		Other:  &Other{ID: 2, Email: "other@example.com"},
+		Tagged: Tagged{Email: "contact@example.com"},
+		Label:  "label",
+		Name:   "user",
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name: "flatten",
+			expected: []string{
+				"Name",
+				"Base.ID", "Base.Name", "Base.Audit.Created", "Base.Audit.Name",
+				"Other.ID", "Other.Email",
+				"Tagged.Email",
+			},
+		},
+		{
+			name: "promote",
+			opts: []Option{WithEmbedMode(EmbedPromote)},
+			expected: []string{
+				"Tagged", "Tagged.Email",
+				"Label",
+				"Name",
+				"Base.Audit.Created",
+				"Other.Email",
+			},
+		},
+		{
+			name: "promote not nested",
+			opts: []Option{WithEmbedMode(EmbedPromote), WithNested(false)},
+			expected: []string{
+				"Tagged",
+				"Label",
+				"Name",
+				"Base.Audit.Created",
+				"Other.Email",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Get("json", input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths(tags))
+		})
+	}
+}
+
+func TestGetEmbedPromoteRules(t *testing.T) {
+	type Left struct {
+		Value string `x:"value"`
+		Note  string
+	}
+	type Right struct {
+		Value string `x:"value"`
+		Note  string `x:"Note"`
+	}
+	type Deep struct {
+		Right
+	}
+	type Ambiguous struct {
+		Left
+		Right
+	}
+	type Shallow struct {
+		Left
+		Deep
+	}
+
+	tests := []struct {
+		name     string
+		data     any
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "ambiguous tagged fields are dropped",
+			data:     Ambiguous{},
+			expected: []string{"Right.Note"},
+		},
+		{
+			name:     "tagged field wins at the same depth",
+			data:     Ambiguous{},
+			opts:     []Option{WithUntagged(true)},
+			expected: []string{"Right.Note"},
+		},
+		{
+			name:     "shallower field wins",
+			data:     Shallow{},
+			expected: []string{"Left.Value", "Deep.Right.Note"},
+		},
+		{
+			name:     "shallower untagged field wins over deeper tagged field",
+			data:     Shallow{},
+			opts:     []Option{WithUntagged(true)},
+			expected: []string{"Left.Value", "Left.Note"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithEmbedMode(EmbedPromote)}, tt.opts...)
+			tags, err := Get("x", tt.data, opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths(tags))
+		})
+	}
+}
+
+func TestGetEmbedPromoteProcess(t *testing.T) {
+	type Base struct {
+		Name string `query:"name"`
+		Page string `query:"page"`
+	}
+	type Request struct {
+		Base
+		Name string `query:"name"`
+	}
+
+	var request Request
+	processor := &keyProcessor{key: "query"}
+	tags, err := Get("query", &request, WithProcessor(processor), WithEmbedMode(EmbedPromote))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Base.Page"}, paths(tags))
+	assert.Equal(t, []string{"Name", "Base.Page"}, processor.fields)
+	assert.Equal(t, "query_name", request.Name)
+	assert.Equal(t, "", request.Base.Name)
+	assert.Equal(t, "query_page", request.Page)
+}
+
+func TestGetMultiEmbedPromote(t *testing.T) {
+	type Inner struct {
+		Value string `json:"value" db:"value"`
+	}
+	type Outer struct {
+		Inner `json:"inner"`
+		Value string `db:"value"`
+	}
+
+	tags, err := GetMulti([]string{"json", "db"}, Outer{}, WithEmbedMode(EmbedPromote))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Inner", "Inner.Value"}, paths(tags["json"]))
+	assert.Equal(t, []string{"Value"}, paths(tags["db"]))
+}
+
+func TestIndexKey(t *testing.T) {
+	assert.Equal(t, "3", indexKey(nil, 3))
+	assert.Equal(t, "0.12.3", indexKey([]int{0, 12}, 3))
+}
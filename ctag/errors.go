@@ -0,0 +1,246 @@
+package ctag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// ErrNotStruct is returned when tags are requested from a value that is not a struct.
+	ErrNotStruct = errors.New("ctag: expected input to be a struct")
+	// ErrNotPointer is returned by SetField when the field is not a pointer, and by ApplyDefaults
+	// when the data is not a pointer.
+	ErrNotPointer = errors.New("ctag: field must be a pointer")
+	// ErrNilPointer is returned by SetField when the field pointer is nil.
+	ErrNilPointer = errors.New("ctag: field pointer is nil")
+	// ErrNotSettable is returned by SetField when the field cannot be set.
+	ErrNotSettable = errors.New("ctag: field is not settable")
+	// ErrCycle is matched by a *CycleError.
+	ErrCycle = errors.New("ctag: cycle detected")
+	// ErrMaxDepth is matched by a *DepthError.
+	ErrMaxDepth = errors.New("ctag: maximum depth exceeded")
+	// ErrUnknownRule is returned by Validate when a validate tag names a rule that is not registered.
+	ErrUnknownRule = errors.New("ctag: unknown validation rule")
+	// ErrUnknownField is returned by Validate when a rule parameter names a field that does
+	// not exist, as in eqfield=Passwrd.
+	ErrUnknownField = errors.New("ctag: unknown field")
+)
+
+// FieldError describes a failure associated with a single tagged struct field.
+// It is returned when a tag cannot be parsed or a TagProcessor fails, and when
+// SetField fails to populate a struct field from a map.
+//
+// Fields:
+//
+//	Path - The dotted Go path to the field from the root struct.
+//	Key  - The tag key that was being processed.
+//	Name - The tag name associated with Key on the field.
+//	Err  - The underlying error.
+//
+// Example usage:
+//
+//	_, err := GetTagsAndProcess("query", &request, processor)
+//
+//	var fieldErr *FieldError
+//	if errors.As(err, &fieldErr) {
+//	    fmt.Printf("invalid parameter %s: %v\n", fieldErr.Name, fieldErr.Err)
+//	}
+type FieldError struct {
+	Path string // Path is the dotted Go path to the field.
+	Key  string // Key is the tag key that was being processed.
+	Name string // Name is the tag name associated with Key.
+	Err  error  // Err is the underlying error.
+}
+
+// Error returns a string representation of the FieldError.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("ctag: field %s (%s:%q): %v", e.Path, e.Key, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors is a collection of field errors returned when processing continues past
+// failing fields, such as by GetTagsAndProcessAll. It follows the semantics of errors.Join:
+// errors.Is and errors.As match against each contained *FieldError.
+//
+// Example usage:
+//
+//	_, err := GetTagsAndProcessAll("form", &form, processor)
+//
+//	var fieldErrs FieldErrors
+//	if errors.As(err, &fieldErrs) {
+//	    for _, fe := range fieldErrs {
+//	        fmt.Printf("%s: %v\n", fe.Path, fe.Err)
+//	    }
+//	}
+type FieldErrors []*FieldError
+
+// Error returns the messages of all contained errors, separated by newlines.
+func (e FieldErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the contained errors.
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// ConversionError describes a failure to convert a value to the type of a field.
+// It is returned by SetField, possibly wrapped, when a value cannot be converted.
+//
+// Fields:
+//
+//	Value - The source value that could not be converted.
+//	Type  - The target type of the conversion.
+//	Err   - The underlying error, such as a *strconv.NumError, or nil if the types are incompatible.
+//
+// Example usage:
+//
+//	err := SetField(&request.Limit, "ten")
+//
+//	var convErr *ConversionError
+//	if errors.As(err, &convErr) {
+//	    fmt.Printf("%v is not a valid %v\n", convErr.Value, convErr.Type)
+//	}
+type ConversionError struct {
+	Value any          // Value is the source value.
+	Type  reflect.Type // Type is the target type.
+	Err   error        // Err is the underlying error, if any.
+}
+
+// Error returns a string representation of the ConversionError.
+func (e *ConversionError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("ctag: cannot convert %T to %v", e.Value, e.Type)
+	}
+	if s, ok := e.Value.(string); ok {
+		return fmt.Sprintf("ctag: cannot parse %q as %v: %v", s, e.Type, e.Err)
+	}
+	return fmt.Sprintf("ctag: cannot convert %T to %v: %v", e.Value, e.Type, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// CycleError is returned with LimitError when a traversal reaches a struct or map that
+// is already being traversed further up the current path.
+//
+// Fields:
+//
+//	Path - The dotted Go path at which the cycle was detected.
+//	Type - The type of the struct or map that closes the cycle.
+type CycleError struct {
+	Path string       // Path is where the cycle was detected.
+	Type reflect.Type // Type is the type of the repeated value.
+}
+
+// Error returns a string representation of the CycleError.
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%v at %s (%v)", ErrCycle, e.Path, e.Type)
+}
+
+// Is reports whether target is ErrCycle.
+func (e *CycleError) Is(target error) bool {
+	return target == ErrCycle
+}
+
+// DepthError is returned with LimitError when a traversal would descend past the
+// depth set with WithMaxDepth.
+//
+// Fields:
+//
+//	Path     - The dotted Go path of the field that was not descended into.
+//	MaxDepth - The configured maximum depth.
+type DepthError struct {
+	Path     string // Path is the field that was not descended into.
+	MaxDepth int    // MaxDepth is the configured maximum depth.
+}
+
+// Error returns a string representation of the DepthError.
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("%v at %s (max %d)", ErrMaxDepth, e.Path, e.MaxDepth)
+}
+
+// Is reports whether target is ErrMaxDepth.
+func (e *DepthError) Is(target error) bool {
+	return target == ErrMaxDepth
+}
+
+// ValidationError describes a field that violates one rule of its validate tag.
+//
+// Fields:
+//
+//	Path  - The dotted Go path to the field from the root struct.
+//	Rule  - The name of the violated rule, such as "min".
+//	Param - The parameter of the rule, such as "1" in min=1, or empty if it has none.
+//	Value - The value of the field, or nil for a nil pointer.
+//	Err   - The error returned by the rule, describing the violation.
+//
+// Example usage:
+//
+//	err := Validate(&request)
+//
+//	var validationErrs ValidationErrors
+//	if errors.As(err, &validationErrs) {
+//	    for _, ve := range validationErrs {
+//	        fmt.Printf("%s: %v\n", ve.Path, ve.Err)
+//	    }
+//	}
+type ValidationError struct {
+	Path  string // Path is the dotted Go path to the field.
+	Rule  string // Rule is the name of the violated rule.
+	Param string // Param is the parameter of the rule.
+	Value any    // Value is the value of the field.
+	Err   error  // Err describes the violation.
+}
+
+// Error returns a string representation of the ValidationError.
+func (e *ValidationError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+	return fmt.Sprintf("ctag: field %s failed %s: %v", e.Path, rule, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is the collection of every rule violation found by Validate. Like
+// FieldErrors, it follows the semantics of errors.Join.
+type ValidationErrors []*ValidationError
+
+// Error returns the messages of all contained errors, separated by newlines.
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the contained errors.
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,153 @@
+package ctag
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errInvalid = errors.New("invalid value")
+
+type failingProcessor struct {
+	fail map[string]error
+}
+
+func (p *failingProcessor) Process(field any, tag *CTag) error {
+	return p.fail[tag.Name]
+}
+
+func TestFieldError(t *testing.T) {
+	type Address struct {
+		Zip string `query:"zip"`
+	}
+	input := struct {
+		Name    string  `query:"name"`
+		Address Address `query:"address"`
+	}{}
+
+	p := &failingProcessor{fail: map[string]error{"zip": errInvalid}}
+	tags, err := GetTagsAndProcess("query", &input, p)
+	assert.Nil(t, tags)
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Address.Zip", fieldErr.Path)
+	assert.Equal(t, "query", fieldErr.Key)
+	assert.Equal(t, "zip", fieldErr.Name)
+	assert.ErrorIs(t, err, errInvalid)
+	assert.EqualError(t, err, `ctag: field Address.Zip (query:"zip"): invalid value`)
+}
+
+func TestFieldErrorMalformedTag(t *testing.T) {
+	input := struct {
+		Desc string `doc:"desc,text='a'b"`
+	}{}
+
+	_, err := GetTags("doc", input)
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Desc", fieldErr.Path)
+	assert.Equal(t, "doc", fieldErr.Key)
+	assert.ErrorContains(t, err, "after quoted value")
+}
+
+func TestConversionError(t *testing.T) {
+	tests := []struct {
+		name         string
+		field        any
+		value        any
+		expectedType reflect.Type
+		expectedMsg  string
+		expectedErr  error
+	}{
+		{
+			name:         "invalid string to int",
+			field:        new(int),
+			value:        "abc",
+			expectedType: reflect.TypeOf(0),
+			expectedMsg:  `ctag: cannot parse "abc" as int: strconv.ParseInt: parsing "abc": invalid syntax`,
+			expectedErr:  strconv.ErrSyntax,
+		},
+		{
+			name:         "incompatible types",
+			field:        new(int),
+			value:        true,
+			expectedType: reflect.TypeOf(0),
+			expectedMsg:  "ctag: cannot convert bool to int",
+		},
+		{
+			name:         "incompatible struct",
+			field:        new(struct{ Name string }),
+			value:        42,
+			expectedType: reflect.TypeOf(struct{ Name string }{}),
+			expectedMsg:  "ctag: cannot convert int to struct { Name string }",
+		},
+		{
+			name:         "slice element",
+			field:        new([]int),
+			value:        "1,x",
+			expectedType: reflect.TypeOf(0),
+			expectedMsg:  `ctag: error converting slice element 1: ctag: cannot parse "x" as int: strconv.ParseInt: parsing "x": invalid syntax`,
+			expectedErr:  strconv.ErrSyntax,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetField(tt.field, tt.value)
+			assert.EqualError(t, err, tt.expectedMsg)
+
+			var convErr *ConversionError
+			assert.True(t, errors.As(err, &convErr))
+			assert.Equal(t, tt.expectedType, convErr.Type)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestConversionErrorFromMap(t *testing.T) {
+	var target struct {
+		Age int `json:"age"`
+	}
+
+	err := SetField(&target, map[string]any{"age": "old"})
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Age", fieldErr.Path)
+	assert.Equal(t, "json", fieldErr.Key)
+	assert.Equal(t, "age", fieldErr.Name)
+
+	var convErr *ConversionError
+	assert.True(t, errors.As(err, &convErr))
+	assert.Equal(t, "old", convErr.Value)
+	assert.Equal(t, reflect.TypeOf(0), convErr.Type)
+}
+
+func TestSentinelErrors(t *testing.T) {
+	_, err := GetTags("query", 42)
+	assert.ErrorIs(t, err, ErrNotStruct)
+
+	assert.ErrorIs(t, SetField("value", "hello"), ErrNotPointer)
+	assert.ErrorIs(t, SetField((*string)(nil), "hello"), ErrNilPointer)
+}
+
+func TestValidationErrors(t *testing.T) {
+	errs := ValidationErrors{
+		{Path: "Name", Rule: "required", Err: errors.New("is required")},
+		{Path: "Items[0].Qty", Rule: "min", Param: "1", Value: 0, Err: errInvalid},
+	}
+
+	assert.EqualError(t, errs, "ctag: field Name failed required: is required\nctag: field Items[0].Qty failed min=1: invalid value")
+	assert.ErrorIs(t, errs, errInvalid)
+
+	var validationErr *ValidationError
+	assert.True(t, errors.As(error(errs), &validationErr))
+	assert.Equal(t, "Name", validationErr.Path)
+}
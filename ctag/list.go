@@ -0,0 +1,143 @@
+package ctag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultSeparators are the separators of slices parsed from strings when none are set with
+// WithSeparators or WithTag, indexed by nesting depth from the innermost slice outwards, so
+// that "a,b;c" sets a [][]string to [[a b] [c]].
+var defaultSeparators = []string{",", ";", "|"}
+
+// blanks are the characters separating elements when the separator is blank.
+const blanks = " \t\r\n"
+
+// errUnterminatedQuote is returned when a quoted list element has no closing quote.
+var errUnterminatedQuote = errors.New("unterminated quoted element")
+
+// listDepth returns the number of nested slice or array levels of t, which is 1 for []int and
+// 2 for [][]int. With an encoding set, byte slices and arrays are decoded from strings as a
+// whole, so they count as single values.
+func (s *setter) listDepth(t reflect.Type) int {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return 0
+	}
+	if t.Elem().Kind() == reflect.Uint8 && s.encoding != "" {
+		return 0
+	}
+	return 1 + s.listDepth(t.Elem())
+}
+
+// separator returns the separator of slices with the given depth. Separators set with
+// WithSeparators or WithTag apply to the innermost levels, and any outer level they leave
+// uncovered falls back to defaultSeparators.
+func (s *setter) separator(depth int) (string, error) {
+	if depth <= len(s.separators) {
+		return s.separators[len(s.separators)-depth], nil
+	}
+	if depth <= len(defaultSeparators) {
+		return defaultSeparators[depth-1], nil
+	}
+	return "", fmt.Errorf("no separator for slices nested %d levels deep", depth)
+}
+
+// tagSeparators returns the separators set by the sep options of tag, outermost first as for
+// WithSeparators, or nil if it sets none. The whole value of sep= separates the innermost level,
+// sep2= the level around it, sep3= the next and so on. Levels left out between them keep their
+// defaults, and levels are read up to the first one left out that has no default.
+func tagSeparators(tag *CTag) []string {
+	var seps []string
+	set := 0
+	for level := 1; ; level++ {
+		name := "sep"
+		if level > 1 {
+			name += strconv.Itoa(level)
+		}
+		sep, ok := tag.Option(name)
+		if ok && sep != "" {
+			set = level
+		} else if level <= len(defaultSeparators) {
+			sep = defaultSeparators[level-1]
+		} else {
+			break
+		}
+		seps = append(seps, sep)
+	}
+	if set == 0 {
+		return nil
+	}
+	seps = seps[:set]
+	slices.Reverse(seps)
+	return seps
+}
+
+// splitList splits str into the elements separated by sep, trimming the space around each.
+// Separators between double quotes do not split, so that `"a,b",c` has two elements; the
+// quotes are kept for unquote or a nested split to handle. A blank sep splits on runs of
+// whitespace.
+func splitList(str string, sep string) ([]string, error) {
+	blank := strings.TrimSpace(sep) == ""
+	if blank {
+		str = strings.TrimSpace(str)
+	}
+
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(str); {
+		switch {
+		case str[i] == '"':
+			quoted = !quoted
+			i++
+		case quoted:
+			i++
+		case blank && strings.IndexByte(blanks, str[i]) >= 0:
+			parts = append(parts, strings.TrimSpace(str[start:i]))
+			for i < len(str) && strings.IndexByte(blanks, str[i]) >= 0 {
+				i++
+			}
+			start = i
+		case !blank && strings.HasPrefix(str[i:], sep):
+			parts = append(parts, strings.TrimSpace(str[start:i]))
+			i += len(sep)
+			start = i
+		default:
+			i++
+		}
+	}
+	if quoted {
+		return nil, errUnterminatedQuote
+	}
+	return append(parts, strings.TrimSpace(str[start:])), nil
+}
+
+// cutList slices str around the first instance of sep outside double quotes, returning the
+// text before and after it. If sep does not appear outside quotes, it returns str, "" and false.
+func cutList(str string, sep string) (before, after string, found bool) {
+	quoted := false
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(str[i:], sep):
+			return str[:i], str[i+len(sep):], true
+		}
+	}
+	return str, "", false
+}
+
+// unquote removes the double quotes around an element, as in CSV, where a doubled quote
+// stands for a single one. Elements that are not quoted are returned unchanged.
+func unquote(part string) string {
+	if len(part) < 2 || part[0] != '"' || part[len(part)-1] != '"' {
+		return part
+	}
+	return strings.ReplaceAll(part[1:len(part)-1], `""`, `"`)
+}
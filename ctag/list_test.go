@@ -0,0 +1,173 @@
+package ctag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		name     string
+		str      string
+		sep      string
+		expected []string
+		errMsg   string
+	}{
+		{name: "comma", str: "a, b ,c", sep: ",", expected: []string{"a", "b", "c"}},
+		{name: "multi-character separator", str: "a::b", sep: "::", expected: []string{"a", "b"}},
+		{name: "empty elements", str: "a,,b,", sep: ",", expected: []string{"a", "", "b", ""}},
+		{name: "quoted separator", str: `"a,b",c`, sep: ",", expected: []string{`"a,b"`, "c"}},
+		{name: "escaped quote", str: `"say ""hi"", then",c`, sep: ",", expected: []string{`"say ""hi"", then"`, "c"}},
+		{name: "blank separator", str: "  a\tb   c ", sep: " ", expected: []string{"a", "b", "c"}},
+		{name: "blank separator with quotes", str: `a "b c"`, sep: " ", expected: []string{"a", `"b c"`}},
+		{name: "unterminated quote", str: `"a,b`, sep: ",", errMsg: "unterminated quoted element"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := splitList(tt.str, tt.sep)
+			if tt.errMsg != "" {
+				assert.EqualError(t, err, tt.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, parts)
+		})
+	}
+}
+
+func TestSetFieldSeparators(t *testing.T) {
+	tests := []setFieldCase{
+		{
+			name:     "quoted element",
+			field:    func() any { return new([]string) },
+			value:    `"a,b",c`,
+			expected: []string{"a,b", "c"},
+		},
+		{
+			name:     "quoted element with spaces and quotes",
+			field:    func() any { return new([]string) },
+			value:    `" a ""b"" ", c`,
+			expected: []string{` a "b" `, "c"},
+		},
+		{
+			name:     "pipe separator",
+			field:    func() any { return new([]string) },
+			value:    "a,b|c",
+			opts:     []SetOption{WithSeparators("|")},
+			expected: []string{"a,b", "c"},
+		},
+		{
+			name:     "space separator",
+			field:    func() any { return new([]int) },
+			value:    "1  2 3",
+			opts:     []SetOption{WithSeparators(" ")},
+			expected: []int{1, 2, 3},
+		},
+		{
+			name:     "nested default separators",
+			field:    func() any { return new([][]string) },
+			value:    "a,b;c",
+			expected: [][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			name:     "three levels",
+			field:    func() any { return new([][][]int) },
+			value:    "1,2;3|4",
+			expected: [][][]int{{{1, 2}, {3}}, {{4}}},
+		},
+		{
+			name:     "nested quoted elements",
+			field:    func() any { return new([][]string) },
+			value:    `"x;y",z;w`,
+			expected: [][]string{{"x;y", "z"}, {"w"}},
+		},
+		{
+			name:     "nested custom separators",
+			field:    func() any { return new([][]int) },
+			value:    "1 2|3 4",
+			opts:     []SetOption{WithSeparators("|", " ")},
+			expected: [][]int{{1, 2}, {3, 4}},
+		},
+		{
+			name:     "inner separator only",
+			field:    func() any { return new([][]int) },
+			value:    "1 2;3",
+			opts:     []SetOption{WithSeparators(" ")},
+			expected: [][]int{{1, 2}, {3}},
+		},
+		{
+			name:     "nested arrays",
+			field:    func() any { return new([][2]int) },
+			value:    "1,2;3,4",
+			expected: [][2]int{{1, 2}, {3, 4}},
+		},
+		{
+			name:     "encoded byte slices are single elements",
+			field:    func() any { return new([][]byte) },
+			value:    "ab,c",
+			opts:     []SetOption{WithEncoding(EncodingRaw)},
+			expected: [][]byte{[]byte("ab"), []byte("c")},
+		},
+		{
+			name:     "byte slices are nested lists",
+			field:    func() any { return new([][]byte) },
+			value:    "1,2;3",
+			expected: [][]byte{{1, 2}, {3}},
+		},
+		{
+			name:   "unterminated quote",
+			field:  func() any { return new([]string) },
+			value:  `"a,b`,
+			errMsg: `ctag: cannot parse "\"a,b" as []string: unterminated quoted element`,
+		},
+		{
+			name:   "too deeply nested",
+			field:  func() any { return new([][][][]int) },
+			value:  "1",
+			errMsg: `ctag: cannot parse "1" as [][][][]int: no separator for slices nested 4 levels deep`,
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestSetFieldSeparatorsWithTag(t *testing.T) {
+	type Request struct {
+		Tags   []string          `query:"tags,sep='|'"`
+		Words  []string          `query:"words,sep=' '"`
+		Matrix [][]int           `query:"matrix,sep2=';'"`
+		Pairs  [][]string        `query:"pairs"`
+		Names  []string          `query:"names,sep=', '"`
+		Ors    []string          `query:"ors,sep='||'"`
+		Grid   [][]int           `query:"grid,sep=' ',sep2='||'"`
+		Cube   [][][]int         `query:"cube,sep3='/'"`
+		Labels map[string]string `query:"labels,sep=';'"`
+	}
+
+	p := &taggedProcessor{values: map[string]string{
+		"tags":   `go|"a|b"|web`,
+		"words":  "hello  world",
+		"matrix": "1,2;3,4",
+		"pairs":  "a,b;c,d",
+		"names":  "Doe, Jane, Roe,Rick",
+		"ors":    "a||b|c",
+		"grid":   "1 2||3 4",
+		"cube":   "1,2;3/4",
+		"labels": "team=a,b;tier=1",
+	}}
+
+	var request Request
+	_, err := Get("query", &request, WithProcessor(p))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "a|b", "web"}, request.Tags)
+	assert.Equal(t, []string{"hello", "world"}, request.Words)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, request.Matrix)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, request.Pairs)
+	assert.Equal(t, []string{"Doe", "Jane", "Roe,Rick"}, request.Names)
+	assert.Equal(t, []string{"a", "b|c"}, request.Ors)
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}}, request.Grid)
+	assert.Equal(t, [][][]int{{{1, 2}, {3}}, {{4}}}, request.Cube)
+	assert.Equal(t, map[string]string{"team": "a,b", "tier": "1"}, request.Labels)
+}
@@ -0,0 +1,108 @@
+package ctag
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Words parsed as booleans with ParseExtended, in addition to those accepted by strconv.ParseBool.
+var (
+	defaultTrueWords  = []string{"yes", "y", "on", "enable", "enabled"}
+	defaultFalseWords = []string{"no", "n", "off", "disable", "disabled"}
+)
+
+// setNumberLiteral sets the numeric fieldVal from a literal accepted by ParseExtended. Integers
+// are parsed with their base prefix and underscores, anything else as a float, divided by 100
+// if it ends in "%", and the result is converted with the range checks of setNumericValue.
+// Leading zeros are ignored rather than read as an octal prefix, so "010" is 10.
+func (s *setter) setNumberLiteral(fieldVal reflect.Value, str string) error {
+	lit := strings.TrimSpace(str)
+	lit, percent := strings.CutSuffix(lit, "%")
+	if percent {
+		lit = strings.TrimSpace(lit)
+	}
+
+	var value any
+	if !percent {
+		digits := trimLeadingZeros(lit)
+		if n, err := strconv.ParseInt(digits, 0, 64); err == nil {
+			value = n
+		} else if n, err := strconv.ParseUint(digits, 0, 64); err == nil {
+			value = n
+		}
+	}
+	if value == nil {
+		f, err := strconv.ParseFloat(lit, 64)
+		if err != nil {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+		}
+		if percent {
+			f /= 100
+		}
+		value = f
+	}
+
+	if err := s.setNumericValue(fieldVal, reflect.ValueOf(value)); err != nil {
+		var convErr *ConversionError
+		if errors.As(err, &convErr) {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: convErr.Err}
+		}
+		return err
+	}
+	return nil
+}
+
+// trimLeadingZeros removes the zeros, and underscores between them, leading the digits of the
+// integer literal lit, keeping its sign, so that only an explicit 0x, 0o or 0b prefix selects
+// a base other than 10 when lit is parsed with base 0. Literals with a prefix, made of zeros
+// only, or with consecutive underscores among the leading zeros are returned unchanged, so that
+// strconv still rejects the malformed ones.
+func trimLeadingZeros(lit string) string {
+	sign, digits := "", lit
+	if digits != "" && (digits[0] == '+' || digits[0] == '-') {
+		sign, digits = digits[:1], digits[1:]
+	}
+	if !strings.HasPrefix(digits, "0") {
+		return lit
+	}
+	trimmed := strings.TrimLeft(digits, "0_")
+	if trimmed == "" || trimmed[0] < '0' || trimmed[0] > '9' {
+		return lit
+	}
+	if strings.Contains(digits[:len(digits)-len(trimmed)], "__") {
+		return lit
+	}
+	return sign + trimmed
+}
+
+// parseBool parses str with strconv.ParseBool, and with ParseExtended also as one of the
+// default words or those set with WithBoolWords, ignoring case and surrounding spaces.
+func (s *setter) parseBool(str string) (bool, error) {
+	if s.parseMode != ParseExtended {
+		return strconv.ParseBool(str)
+	}
+
+	word := strings.TrimSpace(str)
+	if b, err := strconv.ParseBool(strings.ToLower(word)); err == nil {
+		return b, nil
+	}
+	if containsFold(defaultTrueWords, word) || containsFold(s.trueWords, word) {
+		return true, nil
+	}
+	if containsFold(defaultFalseWords, word) || containsFold(s.falseWords, word) {
+		return false, nil
+	}
+	return false, &strconv.NumError{Func: "ParseBool", Num: str, Err: strconv.ErrSyntax}
+}
+
+// containsFold reports whether words contains word, ignoring case.
+func containsFold(words []string, word string) bool {
+	for _, w := range words {
+		if strings.EqualFold(w, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package ctag
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFieldParseMode(t *testing.T) {
+	extended := WithParseMode(ParseExtended)
+
+	tests := []setFieldCase{
+		{name: "hex int", field: func() any { return new(int) }, value: "0x1F", opts: []SetOption{extended}, expected: 31},
+		{name: "octal int", field: func() any { return new(int) }, value: "0o17", opts: []SetOption{extended}, expected: 15},
+		{name: "binary uint", field: func() any { return new(uint8) }, value: "0b1010", opts: []SetOption{extended}, expected: uint8(10)},
+		{name: "negative hex int", field: func() any { return new(int16) }, value: "-0x10", opts: []SetOption{extended}, expected: int16(-16)},
+		{name: "leading zero", field: func() any { return new(int) }, value: "010", opts: []SetOption{extended}, expected: 10},
+		{name: "negative leading zeros", field: func() any { return new(int64) }, value: "-007", opts: []SetOption{extended}, expected: int64(-7)},
+		{name: "leading zero uint", field: func() any { return new(uint) }, value: "0_19", opts: []SetOption{extended}, expected: uint(19)},
+		{name: "zero", field: func() any { return new(int) }, value: "00", opts: []SetOption{extended}, expected: 0},
+		{name: "underscores", field: func() any { return new(int) }, value: "1_000_000", opts: []SetOption{extended}, expected: 1000000},
+		{name: "exponent int", field: func() any { return new(int) }, value: "1e3", opts: []SetOption{extended}, expected: 1000},
+		{name: "exponent uint", field: func() any { return new(uint32) }, value: "2.5e2", opts: []SetOption{extended}, expected: uint32(250)},
+		{name: "surrounding spaces", field: func() any { return new(int) }, value: " 42 ", opts: []SetOption{extended}, expected: 42},
+		{name: "max uint64", field: func() any { return new(uint64) }, value: "0xFFFF_FFFF_FFFF_FFFF", opts: []SetOption{extended}, expected: uint64(1<<64 - 1)},
+		{name: "float with underscores", field: func() any { return new(float64) }, value: "1_000.5", opts: []SetOption{extended}, expected: 1000.5},
+		{name: "float from hex", field: func() any { return new(float64) }, value: "0x10", opts: []SetOption{extended}, expected: 16.0},
+		{name: "percentage", field: func() any { return new(float64) }, value: "10%", opts: []SetOption{extended}, expected: 0.1},
+		{name: "percentage with space", field: func() any { return new(float32) }, value: "50 %", opts: []SetOption{extended}, expected: float32(0.5)},
+		{name: "whole percentage to int", field: func() any { return new(int) }, value: "200%", opts: []SetOption{extended}, expected: 2},
+		{
+			name:   "fractional int",
+			field:  func() any { return new(int) },
+			value:  "1.5",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "1.5" as int: 1.5 has a fractional part`,
+		},
+		{
+			name:   "fractional percentage to int",
+			field:  func() any { return new(int) },
+			value:  "10%",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "10%" as int: 0.1 has a fractional part`,
+		},
+		{
+			name:   "consecutive underscores after leading zero",
+			field:  func() any { return new(int) },
+			value:  "0__1",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "0__1" as int`,
+		},
+		{
+			name:   "trailing underscore after leading zeros",
+			field:  func() any { return new(uint) },
+			value:  "00_",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "00_" as uint`,
+		},
+		{
+			name:   "out of range",
+			field:  func() any { return new(int8) },
+			value:  "0x100",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "0x100" as int8: value out of range`,
+		},
+		{
+			name:   "negative uint",
+			field:  func() any { return new(uint) },
+			value:  "-1",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "-1" as uint: value out of range`,
+		},
+		{
+			name:     "lenient out of range",
+			field:    func() any { return new(int8) },
+			value:    "0x100",
+			opts:     []SetOption{extended, WithLenientNumbers(true)},
+			expected: int8(0),
+		},
+		{
+			name:   "invalid literal",
+			field:  func() any { return new(int) },
+			value:  "0xZZ",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "0xZZ" as int: strconv.ParseFloat: parsing "0xZZ": invalid syntax`,
+		},
+		{
+			name:   "strict rejects hex",
+			field:  func() any { return new(int) },
+			value:  "0x1F",
+			errMsg: `ctag: cannot parse "0x1F" as int`,
+		},
+		{
+			name:   "strict rejects percentage",
+			field:  func() any { return new(float64) },
+			value:  "10%",
+			opts:   []SetOption{WithParseMode(ParseStrict)},
+			errMsg: `ctag: cannot parse "10%" as float64`,
+		},
+		{name: "yes", field: func() any { return new(bool) }, value: "yes", opts: []SetOption{extended}, expected: true},
+		{name: "On", field: func() any { return new(bool) }, value: "On", opts: []SetOption{extended}, expected: true},
+		{name: "OFF", field: func() any { return new(bool) }, value: " OFF ", opts: []SetOption{extended}, expected: false},
+		{name: "disabled", field: func() any { return new(bool) }, value: "disabled", opts: []SetOption{extended}, expected: false},
+		{name: "TRUE", field: func() any { return new(bool) }, value: "TRUE", opts: []SetOption{extended}, expected: true},
+		{
+			name:     "custom true word",
+			field:    func() any { return new(bool) },
+			value:    "Ja",
+			opts:     []SetOption{extended, WithBoolWords([]string{"ja"}, []string{"nein"})},
+			expected: true,
+		},
+		{
+			name:     "custom false word",
+			field:    func() any { return new(bool) },
+			value:    "nein",
+			opts:     []SetOption{extended, WithBoolWords([]string{"ja"}, []string{"nein"})},
+			expected: false,
+		},
+		{
+			name:     "custom words extend defaults",
+			field:    func() any { return new(bool) },
+			value:    "no",
+			opts:     []SetOption{extended, WithBoolWords([]string{"ja"}, []string{"nein"})},
+			expected: false,
+		},
+		{
+			name:   "unknown word",
+			field:  func() any { return new(bool) },
+			value:  "maybe",
+			opts:   []SetOption{extended},
+			errMsg: `ctag: cannot parse "maybe" as bool: strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+		{
+			name:   "strict rejects words",
+			field:  func() any { return new(bool) },
+			value:  "yes",
+			opts:   []SetOption{WithBoolWords([]string{"yes"}, nil)},
+			errMsg: `ctag: cannot parse "yes" as bool`,
+		},
+		{
+			name:     "slice elements",
+			field:    func() any { return new([]int) },
+			value:    "0x1, 1e1, 1_0",
+			opts:     []SetOption{extended},
+			expected: []int{1, 10, 10},
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestSetFieldParseModeRangeError(t *testing.T) {
+	var n int8
+	err := SetField(&n, "1e3", WithParseMode(ParseExtended))
+	assert.ErrorIs(t, err, strconv.ErrRange)
+}
+
+func TestSetFieldParseModeWithTag(t *testing.T) {
+	type Request struct {
+		Limit  int     `query:"limit,parse=extended"`
+		Ratio  float64 `query:"ratio,parse=extended"`
+		Active bool    `query:"active,parse=extended"`
+		Strict int     `query:"strict,parse=strict"`
+	}
+
+	p := &taggedProcessor{values: map[string]string{
+		"limit":  "1_000",
+		"ratio":  "25%",
+		"active": "on",
+		"strict": "12",
+	}}
+
+	var request Request
+	_, err := Get("query", &request, WithProcessor(p))
+	assert.NoError(t, err)
+	assert.Equal(t, Request{Limit: 1000, Ratio: 0.25, Active: true, Strict: 12}, request)
+}
@@ -0,0 +1,115 @@
+package ctag
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NameMapper derives a tag name from the Go name of a struct field.
+// It is used to name untagged fields included with WithUntaggedNaming.
+//
+// The package provides FieldName, SnakeCase, CamelCase, KebabCase and ScreamingSnakeCase,
+// and any function with the same signature can be used as a custom strategy.
+//
+// Example usage:
+//
+//	prefixed := func(name string) string {
+//	    return "APP_" + ScreamingSnakeCase(name)
+//	}
+//
+//	tags, err := Get("env", &config, WithUntaggedNaming(prefixed))
+type NameMapper func(fieldName string) string
+
+// FieldName returns the Go field name unchanged. It is the default NameMapper.
+//
+// Example usage:
+//
+//	FieldName("UserID") // "UserID"
+func FieldName(name string) string {
+	return name
+}
+
+// SnakeCase converts a Go field name to snake_case.
+//
+// Example usage:
+//
+//	SnakeCase("UserID")     // "user_id"
+//	SnakeCase("HTTPServer") // "http_server"
+func SnakeCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "_"))
+}
+
+// ScreamingSnakeCase converts a Go field name to SCREAMING_SNAKE_CASE.
+//
+// Example usage:
+//
+//	ScreamingSnakeCase("UserID")     // "USER_ID"
+//	ScreamingSnakeCase("HTTPServer") // "HTTP_SERVER"
+func ScreamingSnakeCase(name string) string {
+	return strings.ToUpper(strings.Join(splitWords(name), "_"))
+}
+
+// KebabCase converts a Go field name to kebab-case.
+//
+// Example usage:
+//
+//	KebabCase("UserID")     // "user-id"
+//	KebabCase("HTTPServer") // "http-server"
+func KebabCase(name string) string {
+	return strings.ToLower(strings.Join(splitWords(name), "-"))
+}
+
+// CamelCase converts a Go field name to camelCase, starting with a lowercase letter.
+//
+// Example usage:
+//
+//	CamelCase("UserID")     // "userId"
+//	CamelCase("HTTPServer") // "httpServer"
+func CamelCase(name string) string {
+	words := splitWords(name)
+	var sb strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			r := []rune(word)
+			r[0] = unicode.ToUpper(r[0])
+			word = string(r)
+		}
+		sb.WriteString(word)
+	}
+	return sb.String()
+}
+
+// splitWords splits a Go identifier into words at case changes and underscores.
+// A run of uppercase letters is treated as an acronym, and digits stay with the
+// word before them, so "HTTPServer2ID" splits into "HTTP", "Server2" and "ID".
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
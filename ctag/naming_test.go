@@ -0,0 +1,99 @@
+package ctag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMappers(t *testing.T) {
+	tests := []struct {
+		input          string
+		snake          string
+		screamingSnake string
+		kebab          string
+		camel          string
+	}{
+		{input: "Name", snake: "name", screamingSnake: "NAME", kebab: "name", camel: "name"},
+		{input: "UserID", snake: "user_id", screamingSnake: "USER_ID", kebab: "user-id", camel: "userId"},
+		{input: "HTTPServer", snake: "http_server", screamingSnake: "HTTP_SERVER", kebab: "http-server", camel: "httpServer"},
+		{input: "MaxConnsPerHost", snake: "max_conns_per_host", screamingSnake: "MAX_CONNS_PER_HOST", kebab: "max-conns-per-host", camel: "maxConnsPerHost"},
+		{input: "OAuth2Token", snake: "o_auth2_token", screamingSnake: "O_AUTH2_TOKEN", kebab: "o-auth2-token", camel: "oAuth2Token"},
+		{input: "Server2ID", snake: "server2_id", screamingSnake: "SERVER2_ID", kebab: "server2-id", camel: "server2Id"},
+		{input: "Already_Snake", snake: "already_snake", screamingSnake: "ALREADY_SNAKE", kebab: "already-snake", camel: "alreadySnake"},
+		{input: "URL", snake: "url", screamingSnake: "URL", kebab: "url", camel: "url"},
+		{input: "X", snake: "x", screamingSnake: "X", kebab: "x", camel: "x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.input, FieldName(tt.input))
+			assert.Equal(t, tt.snake, SnakeCase(tt.input))
+			assert.Equal(t, tt.screamingSnake, ScreamingSnakeCase(tt.input))
+			assert.Equal(t, tt.kebab, KebabCase(tt.input))
+			assert.Equal(t, tt.camel, CamelCase(tt.input))
+		})
+	}
+}
+
+func TestGetUntaggedNaming(t *testing.T) {
+	type Pool struct {
+		MaxConns int
+	}
+	type Config struct {
+		DatabaseURL string
+		Port        int `env:"PORT"`
+		Pool        Pool
+		Ignored     string `env:"-"`
+		private     string
+	}
+
+	input := Config{DatabaseURL: "postgres://", Port: 8080, Pool: Pool{MaxConns: 10}, private: "x"}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "tagged only",
+			expected: []string{"PORT"},
+		},
+		{
+			name:     "field name",
+			opts:     []Option{WithUntagged(true)},
+			expected: []string{"DatabaseURL", "PORT", "Pool", "MaxConns"},
+		},
+		{
+			name:     "screaming snake",
+			opts:     []Option{WithUntaggedNaming(ScreamingSnakeCase)},
+			expected: []string{"DATABASE_URL", "PORT", "POOL", "MAX_CONNS"},
+		},
+		{
+			name:     "kebab",
+			opts:     []Option{WithUntaggedNaming(KebabCase)},
+			expected: []string{"database-url", "PORT", "pool", "max-conns"},
+		},
+		{
+			name: "custom",
+			opts: []Option{WithUntaggedNaming(func(name string) string {
+				return "APP_" + strings.ToUpper(name)
+			})},
+			expected: []string{"APP_DATABASEURL", "PORT", "APP_POOL", "APP_MAXCONNS"},
+		},
+		{
+			name:     "disabled after naming",
+			opts:     []Option{WithUntaggedNaming(SnakeCase), WithUntagged(false)},
+			expected: []string{"PORT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Get("env", input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tagNames(tags))
+		})
+	}
+}
@@ -0,0 +1,651 @@
+package ctag
+
+// Option configures how Get and GetMulti extract and process tags.
+//
+// Options are applied in order, so a later option overrides an earlier one that
+// configures the same behavior.
+//
+// Example usage:
+//
+//	tags, err := Get("query", &request,
+//	    WithProcessor(processor),
+//	    WithMaxDepth(2),
+//	    WithErrorMode(CollectErrors),
+//	)
+type Option func(*options)
+
+// options holds the configuration built from a list of Option values.
+type options struct {
+	processor   TagProcessor            // processor is applied to each extracted tag, if non-nil.
+	processors  map[string]TagProcessor // processors overrides processor for individual keys.
+	fallbacks   []string                // fallbacks are tag keys consulted in order when a field has no tag for the key.
+	maxDepth    int                     // maxDepth limits how deep nested structs are descended into; 0 means no limit.
+	nested      bool                    // nested reports whether nested struct fields are descended into.
+	collections bool                    // collections reports whether structs held in slices, arrays and maps are descended into.
+	allocate    bool                    // allocate reports whether nil pointers to structs are allocated so their fields can be processed.
+	untagged    bool                    // untagged reports whether fields without a tag for the key are included.
+	naming      NameMapper              // naming derives the names of untagged fields; nil means FieldName.
+	omitEmpty   OmitEmptyMode           // omitEmpty controls how zero-valued fields are skipped.
+	errorMode   ErrorMode               // errorMode controls how processing errors are reported.
+	limitMode   LimitMode               // limitMode controls how cycles and the maximum depth are reported.
+	embedMode   EmbedMode               // embedMode controls how the fields of embedded structs are merged into their parent.
+	qualify     bool                    // qualify reports whether nested struct fields prefix the names of their fields.
+	joiner      string                  // joiner separates the parts of qualified names.
+}
+
+// newOptions returns the default options with opts applied.
+func newOptions(opts []Option) options {
+	o := options{nested: true, joiner: "_"}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// OmitEmptyMode controls how zero-valued fields are skipped during tag extraction.
+type OmitEmptyMode int
+
+const (
+	// OmitEmptyTagged skips zero-valued fields whose tag contains "omitempty". This is the default.
+	OmitEmptyTagged OmitEmptyMode = iota
+	// OmitEmptyNever includes zero-valued fields even if their tag contains "omitempty".
+	OmitEmptyNever
+	// OmitEmptyAlways skips every zero-valued field, whether or not its tag contains "omitempty".
+	OmitEmptyAlways
+)
+
+// ErrorMode controls how errors raised while processing fields are reported.
+type ErrorMode int
+
+const (
+	// FailFast stops at the first failing field and returns its *FieldError. This is the default.
+	FailFast ErrorMode = iota
+	// CollectErrors processes every field and returns the tags that succeeded along with
+	// a FieldErrors value describing every failing field.
+	CollectErrors
+)
+
+// LimitMode controls what happens when a traversal meets a cycle in the data or reaches
+// the depth set with WithMaxDepth.
+type LimitMode int
+
+const (
+	// LimitStop stops descending at the cycle or depth limit and continues with the remaining fields.
+	// This is the default.
+	LimitStop LimitMode = iota
+	// LimitError ends the traversal with a *CycleError or *DepthError.
+	LimitError
+)
+
+// EmbedMode controls how the fields of embedded structs are merged into the fields of their parent.
+type EmbedMode int
+
+const (
+	// EmbedFlatten returns the tags of embedded structs after the fields of their parent,
+	// including every field whose name is also used by another field. This is the default.
+	EmbedFlatten EmbedMode = iota
+	// EmbedPromote applies the visibility rules of Go and encoding/json to promoted fields,
+	// so that each name is returned at most once, and treats tagged embedded structs as named fields.
+	EmbedPromote
+)
+
+// WithProcessor sets the TagProcessor applied to each extracted tag.
+//
+// Example usage:
+//
+//	tags, err := Get("query", &request, WithProcessor(&QueryProcessor{req: req}))
+func WithProcessor(p TagProcessor) Option {
+	return func(o *options) {
+		o.processor = p
+	}
+}
+
+// WithLimitMode sets what happens when a traversal meets a cycle in the data or reaches
+// the depth set with WithMaxDepth. It defaults to LimitStop.
+//
+// Pointer, embedded and map cycles are always detected: a struct or map that is already being
+// traversed further up the current path is not descended into again. The same value reached
+// twice through different paths, as in a shared node of a graph, is not a cycle.
+//
+// Example usage:
+//
+//	type Node struct {
+//	    Value int   `x:"value"`
+//	    Next  *Node `x:"next"`
+//	}
+//
+//	list := &Node{Value: 1}
+//	list.Next = list
+//
+//	_, err := Get("x", list, WithLimitMode(LimitError))
+//	// errors.Is(err, ErrCycle) == true
+func WithLimitMode(mode LimitMode) Option {
+	return func(o *options) {
+		o.limitMode = mode
+	}
+}
+
+// WithProcessors sets a TagProcessor for individual tag keys, for use with GetMulti.
+// Keys without an entry fall back to the processor set with WithProcessor.
+//
+// Example usage:
+//
+//	tags, err := GetMulti([]string{"query", "header"}, &request, WithProcessors(map[string]TagProcessor{
+//	    "query":  &QueryProcessor{req: req},
+//	    "header": &HeaderProcessor{req: req},
+//	}))
+func WithProcessors(processors map[string]TagProcessor) Option {
+	return func(o *options) {
+		o.processors = processors
+	}
+}
+
+// WithFallbackKeys sets tag keys consulted, in order, for fields that have no tag for the
+// requested key. The returned CTag keeps the requested key as its Key and records the key
+// that matched in MatchedKey. Combine with WithUntagged to finally fall back to the Go field name.
+//
+// Example usage:
+//
+//	type User struct {
+//	    ID    int    `sql:"user_id"`
+//	    Name  string `db:"user_name"`
+//	    Email string `json:"email"`
+//	    Age   int
+//	}
+//
+//	tags, err := Get("sql", user, WithFallbackKeys("db", "json"), WithUntagged(true))
+//	// tags[0].Name = "user_id",   tags[0].MatchedKey = "sql"
+//	// tags[1].Name = "user_name", tags[1].MatchedKey = "db"
+//	// tags[2].Name = "email",     tags[2].MatchedKey = "json"
+//	// tags[3].Name = "Age",       tags[3].MatchedKey = ""
+func WithFallbackKeys(keys ...string) Option {
+	return func(o *options) {
+		o.fallbacks = keys
+	}
+}
+
+// WithMaxDepth limits how many levels of nested struct fields are descended into.
+// Fields of the root struct are at depth 0, fields of a nested struct at depth 1, and so on.
+// Embedded structs are flattened into their parent and do not add a level, while a collection
+// nested in another, as in [][]T, adds one. A depth of 0, the default, means no limit. Fields past the limit are not descended into;
+// use WithLimitMode(LimitError) to report this as a *DepthError instead.
+//
+// Example usage:
+//
+//	// Only extract tags from the root struct and its direct nested structs.
+//	tags, err := Get("query", request, WithMaxDepth(1))
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithNested sets whether nested struct fields are descended into. It defaults to true.
+// Embedded structs are always flattened into their parent.
+//
+// Example usage:
+//
+//	tags, err := Get("query", request, WithNested(false))
+func WithNested(nested bool) Option {
+	return func(o *options) {
+		o.nested = nested
+	}
+}
+
+// WithCollections sets whether structs held in slice, array and map fields are descended into,
+// including through pointers such as []*Item. Their tags are returned with element indexes in
+// their Path, such as Items[2].SKU or Meta["k"].Value, and map entries are visited in sorted key order.
+// Each element counts as a level of nesting for WithMaxDepth. It defaults to false.
+//
+// Processors receive settable pointers into slice and array elements. Map values and structs
+// held in interfaces are not addressable, so they are processed through a copy that is stored back.
+//
+// Example usage:
+//
+//	type Order struct {
+//	    Items []Item          `json:"items"`
+//	    Meta  map[string]Meta `json:"meta"`
+//	}
+//
+//	tags, err := Get("json", &order, WithCollections(true))
+//	// tags[1].Path = "Items[0].SKU"
+func WithCollections(collections bool) Option {
+	return func(o *options) {
+		o.collections = collections
+	}
+}
+
+// WithAllocate sets whether nil pointer-to-struct fields, including embedded ones, are
+// allocated so that processors can populate the fields of the struct they point to.
+// After the struct has been processed, the allocation is dropped again and the pointer reset
+// to nil if the struct is still the zero value. It defaults to false.
+//
+// Only settable fields are allocated, so data must be passed by pointer. A struct type that is
+// already being traversed further up the current path is not allocated again, so recursive types
+// such as linked list nodes are left nil rather than allocated without bound.
+//
+// Example usage:
+//
+//	type Request struct {
+//	    Name    string   `query:"name"`
+//	    Address *Address `query:"address"`
+//	}
+//
+//	var request Request
+//	_, err := Get("query", &request, WithProcessor(&QueryProcessor{req: req}), WithAllocate(true))
+//	// request.Address is non-nil only if a query parameter populated one of its fields.
+func WithAllocate(allocate bool) Option {
+	return func(o *options) {
+		o.allocate = allocate
+	}
+}
+
+// WithEmbedMode sets how the fields of embedded structs are merged into their parent.
+// It defaults to EmbedFlatten.
+//
+// With EmbedPromote, fields sharing a name are resolved the way encoding/json resolves them:
+// the field promoted through the fewest embedded structs wins, a tagged field wins over untagged
+// fields at the same depth, and if there is still no single winner, none of them is returned.
+// Hidden fields are not processed. An embedded struct whose tag gives it a name, such as
+// `x:"inner"`, is not flattened but returned and descended into like a nested struct field.
+//
+// Example usage:
+//
+//	type Base struct {
+//	    ID   int    `json:"id"`
+//	    Name string `json:"name"`
+//	}
+//
+//	type User struct {
+//	    Base
+//	    Name  string `json:"name"`
+//	    Audit Audit  `json:"audit"`
+//	}
+//
+//	tags, err := Get("json", user, WithEmbedMode(EmbedPromote))
+//	// tags holds Name, Audit and the fields of Audit, and Base.ID,
+//	// but not Base.Name, which is shadowed by User.Name.
+func WithEmbedMode(mode EmbedMode) Option {
+	return func(o *options) {
+		o.embedMode = mode
+	}
+}
+
+// WithQualifiedNames sets whether the names of fields in nested structs are qualified with the
+// names of the struct fields they are nested in, joined with the joiner set with WithNameJoiner.
+// It defaults to false.
+//
+// Embedded structs and struct fields tagged "inline" are flattened into their parent and do not
+// add to the qualified name; an inline field is not returned itself. An embedded or inline struct
+// field tagged "prefix" qualifies the names of its fields all the same. Structs held in collections,
+// with WithCollections, add their index or map key to a non-empty prefix. Without this option,
+// the "inline" and "prefix" options have no effect and names are returned as tagged.
+//
+// Example usage:
+//
+//	type Config struct {
+//	    DB struct {
+//	        Host string `env:"HOST"`
+//	    } `env:"DB"`
+//	    Log struct {
+//	        Level string `env:"LOG_LEVEL"`
+//	    } `env:",inline"`
+//	}
+//
+//	tags, err := Get("env", &config, WithQualifiedNames(true))
+//	// tags[0].Name = "DB"
+//	// tags[1].Name = "DB_HOST"
+//	// tags[2].Name = "LOG_LEVEL"
+func WithQualifiedNames(qualify bool) Option {
+	return func(o *options) {
+		o.qualify = qualify
+	}
+}
+
+// WithNameJoiner sets the separator, such as "_", "." or "-", placed between the parts of
+// qualified names. It is not repeated after a name that already ends with it, so that
+// `env:"DB_"` and `env:"DB"` both qualify HOST as DB_HOST with "_". It defaults to "_";
+// an empty joiner joins the parts as they are.
+//
+// Example usage:
+//
+//	type Flags struct {
+//	    Server struct {
+//	        Port int `flag:"port"`
+//	    } `flag:"server"`
+//	}
+//
+//	tags, err := Get("flag", &flags, WithQualifiedNames(true), WithNameJoiner("."))
+//	// tags[1].Name = "server.port"
+func WithNameJoiner(joiner string) Option {
+	return func(o *options) {
+		o.joiner = joiner
+	}
+}
+
+// WithUntagged sets whether exported fields without a tag for the key are included.
+// Untagged fields are returned with the Go field name as their Name, unless a different
+// NameMapper is set with WithUntaggedNaming. It defaults to false.
+//
+// Example usage:
+//
+//	type Config struct {
+//	    Host string
+//	    Port int `env:"PORT"`
+//	}
+//
+//	tags, err := Get("env", config, WithUntagged(true))
+//	// tags[0].Name = "Host"
+//	// tags[1].Name = "PORT"
+func WithUntagged(untagged bool) Option {
+	return func(o *options) {
+		o.untagged = untagged
+	}
+}
+
+// WithUntaggedNaming includes exported fields without a tag for the key, like WithUntagged(true),
+// and derives their Name from the Go field name with the given NameMapper.
+//
+// Example usage:
+//
+//	type Config struct {
+//	    DatabaseURL string
+//	    MaxConns    int `env:"POOL_SIZE"`
+//	}
+//
+//	tags, err := Get("env", &config, WithUntaggedNaming(ScreamingSnakeCase))
+//	// tags[0].Name = "DATABASE_URL"
+//	// tags[1].Name = "POOL_SIZE"
+func WithUntaggedNaming(m NameMapper) Option {
+	return func(o *options) {
+		o.untagged = true
+		o.naming = m
+	}
+}
+
+// WithOmitEmpty sets how zero-valued fields are skipped. It defaults to OmitEmptyTagged.
+//
+// Example usage:
+//
+//	// Process every field, including zero-valued fields tagged omitempty.
+//	tags, err := Get("query", &request, WithOmitEmpty(OmitEmptyNever))
+func WithOmitEmpty(mode OmitEmptyMode) Option {
+	return func(o *options) {
+		o.omitEmpty = mode
+	}
+}
+
+// WithErrorMode sets how errors raised while processing fields are reported. It defaults to FailFast.
+//
+// Example usage:
+//
+//	tags, err := Get("form", &form, WithProcessor(processor), WithErrorMode(CollectErrors))
+func WithErrorMode(mode ErrorMode) Option {
+	return func(o *options) {
+		o.errorMode = mode
+	}
+}
+
+// SetOption configures how SetField converts a value.
+//
+// Example usage:
+//
+//	err := SetField(field, value, WithConverters(converters))
+type SetOption func(*setOptions)
+
+// setOptions holds the configuration built from a list of SetOption values.
+type setOptions struct {
+	converters *Converters // converters are consulted before DefaultConverters, if non-nil.
+	layout     string      // layout is the layout of times parsed from strings; empty means RFC3339.
+	lenient    bool        // lenient reports whether numeric conversions truncate instead of failing.
+	encoding   string      // encoding is the encoding of byte slices and arrays set from strings; empty parses them as lists.
+	separators []string    // separators split slices parsed from strings, outermost first; empty means defaultSeparators.
+	parseMode  ParseMode   // parseMode controls which literals numbers and booleans are parsed from.
+	trueWords  []string    // trueWords are parsed as true with ParseExtended, in addition to the defaults.
+	falseWords []string    // falseWords are parsed as false with ParseExtended, in addition to the defaults.
+}
+
+// WithConverters sets a Converters registry consulted before DefaultConverters, so that a
+// single call can add or override conversions without changing them globally.
+//
+// Example usage:
+//
+//	converters := NewConverters()
+//	RegisterConverter(converters, func(s string) (Status, error) {
+//	    return ParseStatus(s)
+//	})
+//
+//	err := SetField(&order.Status, "shipped", WithConverters(converters))
+func WithConverters(c *Converters) SetOption {
+	return func(o *setOptions) {
+		o.converters = c
+	}
+}
+
+// WithTag configures the conversion from the options of the tag of the field being set,
+// so that a TagProcessor can pass its tag through:
+//
+//	layout - the time layout, as for WithTimeLayout
+//	enc    - the encoding of byte slices and arrays, as for WithEncoding
+//	sep    - the separator of slice elements, as for WithSeparators; the whole value separates
+//	         the innermost level, so sep=', ' splits "a, b" and sep='||' splits "a||b"
+//	sep2   - the separator of the level around the innermost one, and sep3 of the next, so
+//	         sep2='|' splits "1,2|3" into [[1 2] [3]]; levels left out keep their defaults
+//	parse  - "strict" or "extended", as for WithParseMode
+//
+// Example usage:
+//
+//	type Request struct {
+//	    Since time.Time `query:"since,layout=DateOnly"`
+//	}
+//
+//	func (p *QueryProcessor) Process(field any, tag *CTag) error {
+//	    return SetField(field, p.req.URL.Query().Get(tag.Name), WithTag(tag))
+//	}
+func WithTag(tag *CTag) SetOption {
+	return func(o *setOptions) {
+		if tag == nil {
+			return
+		}
+		if layout, ok := tag.Option("layout"); ok {
+			o.layout = layout
+		}
+		if enc, ok := tag.Option("enc"); ok {
+			o.encoding = enc
+		}
+		if seps := tagSeparators(tag); seps != nil {
+			o.separators = seps
+		}
+		switch mode, _ := tag.Option("parse"); mode {
+		case "strict":
+			o.parseMode = ParseStrict
+		case "extended":
+			o.parseMode = ParseExtended
+		}
+	}
+}
+
+// WithTimeLayout sets the layout used to parse time.Time values from strings. It may be a
+// layout accepted by time.Parse, the name of one of the layout constants of the time package,
+// such as "RFC1123" or "DateOnly", or LayoutUnix or LayoutUnixMilli to parse Unix timestamps.
+// It defaults to RFC3339.
+//
+// Example usage:
+//
+//	var since time.Time
+//	err := SetField(&since, "2024-01-31", WithTimeLayout(time.DateOnly))
+func WithTimeLayout(layout string) SetOption {
+	return func(o *setOptions) {
+		o.layout = layout
+	}
+}
+
+// WithLenientNumbers sets whether numeric conversions that lose information truncate instead of
+// failing. By default SetField rejects values outside the range of the field's type, such as 300
+// for an int8 or -1 for a uint, with an error matching strconv.ErrRange, and floats with a
+// fractional part for integer fields. Leniently, values wrap and fractions are truncated as in a
+// Go conversion.
+//
+// Example usage:
+//
+//	var n int
+//	err := SetField(&n, 3.9, WithLenientNumbers(true))
+//	// n == 3
+func WithLenientNumbers(lenient bool) SetOption {
+	return func(o *setOptions) {
+		o.lenient = lenient
+	}
+}
+
+// Encodings of byte slices and arrays set from strings, for use with WithEncoding or an enc= tag option.
+const (
+	// EncodingRaw uses the bytes of the string as they are.
+	EncodingRaw = "raw"
+	// EncodingBase64 decodes standard base64, as in RFC 4648, with or without padding.
+	EncodingBase64 = "base64"
+	// EncodingBase64URL decodes URL-safe base64, as in RFC 4648, with or without padding.
+	EncodingBase64URL = "base64url"
+	// EncodingHex decodes hexadecimal.
+	EncodingHex = "hex"
+)
+
+// WithEncoding sets the encoding of strings set into byte slices and byte arrays: EncodingRaw,
+// EncodingBase64, EncodingBase64URL or EncodingHex. It defaults to "", which parses them like any
+// other slice, from comma-separated numbers such as "1,2,3".
+//
+// Example usage:
+//
+//	var key [16]byte
+//	err := SetField(&key, "000102030405060708090a0b0c0d0e0f", WithEncoding(EncodingHex))
+func WithEncoding(enc string) SetOption {
+	return func(o *setOptions) {
+		o.encoding = enc
+	}
+}
+
+// WithSeparators sets the separators of slice elements parsed from strings, one per nesting
+// level of the slice, outermost first. Given fewer separators than levels, they apply to the
+// innermost levels and the others keep their defaults, which are "," for the innermost level,
+// then ";" and "|". A blank separator splits on runs of whitespace. Whatever the separator,
+// an element in double quotes may contain it, and a doubled quote inside stands for one quote.
+//
+// Example usage:
+//
+//	var matrix [][]int
+//	err := SetField(&matrix, "1 2|3 4", WithSeparators("|", " "))
+//	// matrix == [][]int{{1, 2}, {3, 4}}
+func WithSeparators(seps ...string) SetOption {
+	return func(o *setOptions) {
+		o.separators = seps
+	}
+}
+
+// ParseMode controls which literals SetField parses numbers and booleans from.
+type ParseMode int
+
+const (
+	// ParseStrict parses integers in base 10, floats with strconv.ParseFloat and booleans with
+	// strconv.ParseBool. This is the default.
+	ParseStrict ParseMode = iota
+	// ParseExtended additionally accepts the literals people tend to write by hand: integers with
+	// a 0x, 0o or 0b base prefix or with underscores between digits, as in Go source, integers in
+	// exponent notation such as 1e3, percentages such as 10%, which are divided by 100, and the
+	// words yes, y, on, enable and enabled for true and no, n, off, disable and disabled for false,
+	// or those set with WithBoolWords. Surrounding spaces and the case of words are ignored, and
+	// leading zeros do not mark octal, so "010" is 10.
+	ParseExtended
+)
+
+// WithParseMode sets which literals numbers and booleans are parsed from. It defaults to ParseStrict.
+// Whatever the mode, numbers must still be representable in the field's type unless
+// WithLenientNumbers is set, so 1.5e3 sets an int but 1.5 and 50% do not.
+//
+// Example usage:
+//
+//	var limit int
+//	err := SetField(&limit, "0x1F", WithParseMode(ParseExtended))
+//	// limit == 31
+func WithParseMode(mode ParseMode) SetOption {
+	return func(o *setOptions) {
+		o.parseMode = mode
+	}
+}
+
+// WithBoolWords adds words parsed as true and as false with ParseExtended, compared without
+// regard to case. They extend the default words rather than replacing them.
+//
+// Example usage:
+//
+//	var active bool
+//	err := SetField(&active, "ja", WithParseMode(ParseExtended), WithBoolWords([]string{"ja"}, []string{"nein"}))
+func WithBoolWords(truthy, falsy []string) SetOption {
+	return func(o *setOptions) {
+		o.trueWords = append(o.trueWords, truthy...)
+		o.falseWords = append(o.falseWords, falsy...)
+	}
+}
+
+// DefaultsOption configures ApplyDefaults.
+type DefaultsOption func(*defaultsOptions)
+
+// defaultsOptions holds the configuration built from DefaultsOption values.
+type defaultsOptions struct {
+	get       []Option    // get configure the traversal of the struct, as for Get.
+	set       []SetOption // set configure the conversion of every default, before the tag options.
+	overwrite bool        // overwrite reports whether pointers to zero values are replaced.
+}
+
+// newDefaultsOptions returns the default ApplyDefaults options with opts applied.
+func newDefaultsOptions(opts []DefaultsOption) defaultsOptions {
+	var o defaultsOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithGetOptions sets options configuring how ApplyDefaults traverses the struct, as they do
+// for Get, such as WithFallbackKeys, WithMaxDepth or WithEmbedMode. ApplyDefaults always sets
+// its own TagProcessor, so WithProcessor and WithProcessors have no effect.
+//
+// Example usage:
+//
+//	err := ApplyDefaults("env", &config, WithGetOptions(WithFallbackKeys("default"), WithMaxDepth(1)))
+func WithGetOptions(opts ...Option) DefaultsOption {
+	return func(o *defaultsOptions) {
+		o.get = append(o.get, opts...)
+	}
+}
+
+// WithSetOptions sets options configuring how ApplyDefaults converts defaults, as they do for
+// SetField, such as WithParseMode or WithTimeLayout. The options of the tag of each field are
+// applied after them, as with WithTag.
+//
+// Example usage:
+//
+//	err := ApplyDefaults(DefaultKey, &config, WithSetOptions(WithParseMode(ParseExtended)))
+func WithSetOptions(opts ...SetOption) DefaultsOption {
+	return func(o *defaultsOptions) {
+		o.set = append(o.set, opts...)
+	}
+}
+
+// WithOverwriteZero sets whether ApplyDefaults overwrites pointer fields that point to a zero
+// value. Such pointers record that the field was explicitly set to zero, so they keep their
+// value by default. Fields holding a zero value themselves cannot be told apart from fields
+// that were never set, so they always receive their default.
+//
+// Example usage:
+//
+//	type Config struct {
+//	    Retries *int `default:"3"`
+//	}
+//
+//	config := Config{Retries: new(int)}
+//	err := ApplyDefaults(DefaultKey, &config, WithOverwriteZero(true))
+//	// *config.Retries == 3
+func WithOverwriteZero(overwrite bool) DefaultsOption {
+	return func(o *defaultsOptions) {
+		o.overwrite = overwrite
+	}
+}
@@ -0,0 +1,498 @@
+package ctag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type optionsLeaf struct {
+	Value string `query:"value"`
+}
+
+type optionsBranch struct {
+	Leaf  optionsLeaf `query:"leaf"`
+	Count int         `query:"count"`
+}
+
+type optionsRoot struct {
+	ID     int           `query:"id"`
+	Branch optionsBranch `query:"branch"`
+	Note   string        `query:"note,omitempty"`
+	Label  string
+	Hidden string `query:"-"`
+}
+
+func tagNames(tags CTags) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func TestGet(t *testing.T) {
+	input := optionsRoot{
+		ID:     1,
+		Branch: optionsBranch{Leaf: optionsLeaf{Value: "v"}, Count: 2},
+		Label:  "label",
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"id", "branch", "leaf", "value", "count"},
+		},
+		{
+			name:     "max depth 1",
+			opts:     []Option{WithMaxDepth(1)},
+			expected: []string{"id", "branch", "leaf", "count"},
+		},
+		{
+			name:     "max depth 2",
+			opts:     []Option{WithMaxDepth(2)},
+			expected: []string{"id", "branch", "leaf", "value", "count"},
+		},
+		{
+			name:     "not nested",
+			opts:     []Option{WithNested(false)},
+			expected: []string{"id", "branch"},
+		},
+		{
+			name:     "untagged",
+			opts:     []Option{WithUntagged(true)},
+			expected: []string{"id", "branch", "leaf", "value", "count", "Label"},
+		},
+		{
+			name:     "omitempty never",
+			opts:     []Option{WithOmitEmpty(OmitEmptyNever)},
+			expected: []string{"id", "branch", "leaf", "value", "count", "note"},
+		},
+		{
+			name:     "omitempty always",
+			opts:     []Option{WithOmitEmpty(OmitEmptyAlways), WithUntagged(true)},
+			expected: []string{"id", "branch", "leaf", "value", "count", "Label"},
+		},
+		{
+			name:     "later option wins",
+			opts:     []Option{WithNested(false), WithNested(true)},
+			expected: []string{"id", "branch", "leaf", "value", "count"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Get("query", input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tagNames(tags))
+		})
+	}
+}
+
+func TestGetOmitEmptyAlwaysZero(t *testing.T) {
+	input := struct {
+		ID   int    `query:"id"`
+		Name string `query:"name"`
+	}{ID: 1}
+
+	tags, err := Get("query", input, WithOmitEmpty(OmitEmptyAlways))
+	assertTags(t, CTags{{Key: "query", Name: "id", Field: 1}}, tags, err)
+}
+
+func TestGetWithProcessor(t *testing.T) {
+	input := struct {
+		Name string `test:"name"`
+		Age  int    `test:"age"`
+	}{Name: "original", Age: 25}
+
+	tags, err := Get("test", &input, WithProcessor(&setFieldProcessor{}))
+	assert.NoError(t, err)
+	assert.Equal(t, "test_value", input.Name)
+	assert.Equal(t, "test_value", tags[0].Field)
+}
+
+func TestGetErrorMode(t *testing.T) {
+	input := struct {
+		Name string `form:"name"`
+		Age  int    `form:"age"`
+	}{}
+
+	errName := errors.New("bad name")
+	errAge := errors.New("bad age")
+	p := &failingProcessor{fail: map[string]error{"name": errName, "age": errAge}}
+
+	_, err := Get("form", &input, WithProcessor(p))
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Name", fieldErr.Path)
+	assert.NotErrorIs(t, err, errAge)
+
+	_, err = Get("form", &input, WithProcessor(p), WithErrorMode(CollectErrors))
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.Len(t, fieldErrs, 2)
+	assert.ErrorIs(t, err, errName)
+	assert.ErrorIs(t, err, errAge)
+}
+
+func TestGetNotStruct(t *testing.T) {
+	_, err := Get("query", []int{1})
+	assert.ErrorIs(t, err, ErrNotStruct)
+}
+
+func TestGetFallbackKeys(t *testing.T) {
+	type Address struct {
+		City string `db:"city"`
+	}
+	type User struct {
+		ID      int     `sql:"user_id" db:"id"`
+		Name    string  `db:"user_name"`
+		Email   string  `json:"email,omitempty"`
+		Secret  string  `sql:"-" db:"secret"`
+		Address Address `json:"address"`
+		Age     int
+	}
+
+	input := User{ID: 1, Name: "John", Email: "john@example.com", Address: Address{City: "Paris"}, Age: 30}
+
+	tests := []struct {
+		name            string
+		opts            []Option
+		expectedNames   []string
+		expectedMatched []string
+	}{
+		{
+			name:            "no fallback",
+			expectedNames:   []string{"user_id"},
+			expectedMatched: []string{"sql"},
+		},
+		{
+			name:            "db then json",
+			opts:            []Option{WithFallbackKeys("db", "json")},
+			expectedNames:   []string{"user_id", "user_name", "email", "address", "city"},
+			expectedMatched: []string{"sql", "db", "json", "json", "db"},
+		},
+		{
+			name:            "json then db",
+			opts:            []Option{WithFallbackKeys("json", "db")},
+			expectedNames:   []string{"user_id", "user_name", "email", "address", "city"},
+			expectedMatched: []string{"sql", "db", "json", "json", "db"},
+		},
+		{
+			name:            "field name last",
+			opts:            []Option{WithFallbackKeys("db", "json"), WithUntagged(true)},
+			expectedNames:   []string{"user_id", "user_name", "email", "address", "city", "Age"},
+			expectedMatched: []string{"sql", "db", "json", "json", "db", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Get("sql", input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedNames, tagNames(tags))
+
+			matched := make([]string, len(tags))
+			for i, tag := range tags {
+				assert.Equal(t, "sql", tag.Key)
+				matched[i] = tag.MatchedKey
+			}
+			assert.Equal(t, tt.expectedMatched, matched)
+		})
+	}
+}
+
+func TestGetFallbackKeysOptions(t *testing.T) {
+	input := struct {
+		Email string `json:"email,omitempty"`
+		Name  string `json:"name,omitempty"`
+	}{Name: "John"}
+
+	tags, err := Get("sql", input, WithFallbackKeys("json"))
+	assert.NoError(t, err)
+	assert.Len(t, tags, 1)
+	assert.Equal(t, "name", tags[0].Name)
+	assert.Equal(t, []string{"omitempty"}, tags[0].Options)
+	assert.Equal(t, "json", tags[0].MatchedKey)
+}
+
+type listNode struct {
+	Value int       `x:"value"`
+	Next  *listNode `x:"next"`
+}
+
+type graphNode struct {
+	Name  string       `x:"name"`
+	Edges []*graphNode `x:"edges"`
+}
+
+type embeddedNode struct {
+	*embeddedNode
+	Value int `x:"value"`
+}
+
+type mapNode struct {
+	Name     string             `x:"name"`
+	Children map[string]mapNode `x:"children"`
+}
+
+func TestGetCycles(t *testing.T) {
+	t.Run("self-referential list", func(t *testing.T) {
+		list := &listNode{Value: 1}
+		list.Next = list
+
+		tags, err := Get("x", list)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Value", "Next"}, paths(tags))
+
+		_, err = Get("x", list, WithLimitMode(LimitError))
+		assert.ErrorIs(t, err, ErrCycle)
+
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+		assert.Equal(t, "Next", cycleErr.Path)
+		assert.Equal(t, typeOf[listNode](), cycleErr.Type)
+	})
+
+	t.Run("longer list cycle", func(t *testing.T) {
+		c := &listNode{Value: 3}
+		b := &listNode{Value: 2, Next: c}
+		a := &listNode{Value: 1, Next: b}
+		c.Next = a
+
+		tags, err := Get("x", a)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Value", "Next", "Next.Value", "Next.Next", "Next.Next.Value", "Next.Next.Next"}, paths(tags))
+	})
+
+	t.Run("acyclic list", func(t *testing.T) {
+		list := &listNode{Value: 1, Next: &listNode{Value: 2}}
+
+		tags, err := Get("x", list, WithLimitMode(LimitError))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Value", "Next", "Next.Value", "Next.Next"}, paths(tags))
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		shared := &graphNode{Name: "shared"}
+		root := &graphNode{Name: "root"}
+		left := &graphNode{Name: "left", Edges: []*graphNode{shared, root}}
+		right := &graphNode{Name: "right", Edges: []*graphNode{shared}}
+		root.Edges = []*graphNode{left, right}
+
+		tags, err := Get("x", root, WithCollections(true))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"Name", "Edges",
+			"Edges[0].Name", "Edges[0].Edges",
+			"Edges[0].Edges[0].Name", "Edges[0].Edges[0].Edges",
+			"Edges[1].Name", "Edges[1].Edges",
+			"Edges[1].Edges[0].Name", "Edges[1].Edges[0].Edges",
+		}, paths(tags))
+
+		_, err = Get("x", root, WithCollections(true), WithLimitMode(LimitError))
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+		assert.Equal(t, "Edges[0].Edges[1]", cycleErr.Path)
+	})
+
+	t.Run("embedded pointer", func(t *testing.T) {
+		node := &embeddedNode{Value: 1}
+		node.embeddedNode = node
+
+		tags, err := Get("x", node)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Value"}, paths(tags))
+
+		_, err = Get("x", node, WithLimitMode(LimitError))
+		assert.ErrorIs(t, err, ErrCycle)
+	})
+
+	t.Run("map", func(t *testing.T) {
+		children := map[string]mapNode{}
+		children["self"] = mapNode{Name: "child", Children: children}
+		root := mapNode{Name: "root", Children: children}
+
+		tags, err := Get("x", &root, WithCollections(true))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Name", "Children", `Children["self"].Name`, `Children["self"].Children`}, paths(tags))
+
+		_, err = Get("x", &root, WithCollections(true), WithLimitMode(LimitError))
+		assert.ErrorIs(t, err, ErrCycle)
+	})
+
+	t.Run("self-referential slice", func(t *testing.T) {
+		type S struct {
+			Items []any `x:"items"`
+		}
+		s := S{Items: make([]any, 1)}
+		s.Items[0] = s.Items
+
+		tags, err := Get("x", &s, WithCollections(true), WithMaxDepth(50))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Items"}, paths(tags))
+
+		_, err = Get("x", &s, WithCollections(true), WithLimitMode(LimitError))
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+		assert.Equal(t, "Items[0]", cycleErr.Path)
+	})
+
+	t.Run("map in slice", func(t *testing.T) {
+		type S struct {
+			Items []any `x:"items"`
+		}
+		m := map[string]any{}
+		s := S{Items: []any{m}}
+		m["back"] = s.Items
+
+		tags, err := Get("x", &s, WithCollections(true))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Items"}, paths(tags))
+
+		_, err = Get("x", &s, WithCollections(true), WithLimitMode(LimitError))
+		var cycleErr *CycleError
+		assert.True(t, errors.As(err, &cycleErr))
+		assert.Equal(t, `Items[0]["back"]`, cycleErr.Path)
+	})
+}
+
+func TestGetMaxDepthNestedCollections(t *testing.T) {
+	type Leaf struct {
+		Name string `x:"name"`
+	}
+	type S struct {
+		Grid [][]Leaf `x:"grid"`
+	}
+	s := S{Grid: [][]Leaf{{{Name: "a"}}}}
+
+	tags, err := Get("x", &s, WithCollections(true), WithMaxDepth(2))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Grid", "Grid[0][0].Name"}, paths(tags))
+
+	tags, err = Get("x", &s, WithCollections(true), WithMaxDepth(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Grid"}, paths(tags))
+
+	_, err = Get("x", &s, WithCollections(true), WithMaxDepth(1), WithLimitMode(LimitError))
+	var depthErr *DepthError
+	assert.True(t, errors.As(err, &depthErr))
+	assert.Equal(t, "Grid[0]", depthErr.Path)
+}
+
+func TestGetMaxDepthLimit(t *testing.T) {
+	list := &listNode{Value: 1, Next: &listNode{Value: 2, Next: &listNode{Value: 3}}}
+
+	tags, err := Get("x", list, WithMaxDepth(1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Value", "Next", "Next.Value", "Next.Next"}, paths(tags))
+
+	_, err = Get("x", list, WithMaxDepth(1), WithLimitMode(LimitError))
+	assert.ErrorIs(t, err, ErrMaxDepth)
+
+	var depthErr *DepthError
+	assert.True(t, errors.As(err, &depthErr))
+	assert.Equal(t, "Next.Next", depthErr.Path)
+	assert.Equal(t, 1, depthErr.MaxDepth)
+
+	_, err = Get("x", list, WithMaxDepth(3), WithLimitMode(LimitError))
+	assert.NoError(t, err)
+
+	_, err = Get("x", list, WithNested(false), WithLimitMode(LimitError))
+	assert.NoError(t, err)
+}
+
+type mapProcessor struct {
+	values map[string]string
+}
+
+func (p *mapProcessor) Process(field any, tag *CTag) error {
+	value, ok := p.values[tag.Name]
+	if !ok {
+		return nil
+	}
+	return SetField(field, value)
+}
+
+func TestGetAllocate(t *testing.T) {
+	type Geo struct {
+		Lat string `query:"lat"`
+	}
+	type Address struct {
+		City string `query:"city"`
+		Geo  **Geo  `query:"geo"`
+	}
+	type Base struct {
+		Trace string `query:"trace"`
+	}
+	type Request struct {
+		*Base
+		Name    string   `query:"name"`
+		Home    *Address `query:"home"`
+		Work    *Address `query:"work"`
+		Count   *int     `query:"count"`
+		Next    *Request `query:"next"`
+		Skipped *Address `query:"-"`
+	}
+
+	p := &mapProcessor{values: map[string]string{"name": "John", "city": "Paris", "lat": "48.8", "trace": "abc"}}
+
+	var plain Request
+	_, err := Get("query", &plain, WithProcessor(p))
+	assert.NoError(t, err)
+	assert.Equal(t, "John", plain.Name)
+	assert.Nil(t, plain.Home)
+	assert.Nil(t, plain.Base)
+
+	var request Request
+	tags, err := Get("query", &request, WithProcessor(p), WithAllocate(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "John", request.Name)
+	assert.NotNil(t, request.Home)
+	assert.Equal(t, "Paris", request.Home.City)
+	assert.Equal(t, "48.8", (*request.Home.Geo).Lat)
+	assert.NotNil(t, request.Base)
+	assert.Equal(t, "abc", request.Trace)
+	assert.NotNil(t, request.Work)
+	assert.Nil(t, request.Count)
+	assert.Nil(t, request.Next)
+	assert.Nil(t, request.Skipped)
+	assert.Contains(t, paths(tags), "Home.Geo.Lat")
+}
+
+func TestGetAllocateDropsUnused(t *testing.T) {
+	type Address struct {
+		City string `query:"city"`
+	}
+	type Request struct {
+		Home *Address `query:"home"`
+		Work *Address `query:"work"`
+	}
+
+	var request Request
+	p := &mapProcessor{values: map[string]string{}}
+	tags, err := Get("query", &request, WithProcessor(p), WithAllocate(true))
+	assert.NoError(t, err)
+	assert.Nil(t, request.Home)
+	assert.Nil(t, request.Work)
+	assert.Equal(t, []string{"Home", "Home.City", "Work", "Work.City"}, paths(tags))
+	assert.Nil(t, tags[0].Field)
+}
+
+func TestGetAllocateNotSettable(t *testing.T) {
+	type Address struct {
+		City string `query:"city"`
+	}
+	type Request struct {
+		Home *Address `query:"home"`
+	}
+
+	tags, err := Get("query", Request{}, WithAllocate(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Home"}, paths(tags))
+}
@@ -0,0 +1,107 @@
+package ctag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseTag splits a raw tag value into its name and options.
+//
+// Items are separated by commas. The first item is the name and the remaining items
+// are options. Any item, or the value following the first '=' of an item, may be
+// wrapped in single quotes to include commas, quotes or spaces. Within a quoted value,
+// a backslash escapes the character that follows it. Quotes appearing anywhere else, and
+// opening quotes that are never closed, are treated literally, so tags written before quoting
+// was supported parse unchanged.
+//
+// Parameters:
+//
+//	tagStr - the raw tag value, as returned by reflect.StructTag.Get
+//
+// Returns:
+//
+//	The tag name, its options with quotes and escapes removed, or an error if a quoted value
+//	is followed by anything other than a comma.
+//
+// Example usage:
+//
+//	name, options, err := ParseTag(`tags,sep=',',text='a, b'`)
+//	// name = "tags"
+//	// options = ["sep=,", "text=a, b"]
+func ParseTag(tagStr string) (string, []string, error) {
+	if !strings.Contains(tagStr, "'") {
+		name, options := parse(tagStr)
+		return name, options, nil
+	}
+
+	var items []string
+	var sb strings.Builder
+	start := true   // start reports whether we are at the beginning of a value.
+	keyed := false  // keyed reports whether the current item already contains '='.
+	closed := false // closed reports whether a quoted value just ended.
+
+	for i := 0; i < len(tagStr); i++ {
+		c := tagStr[i]
+		switch {
+		case c == ',':
+			items = append(items, sb.String())
+			sb.Reset()
+			start, keyed, closed = true, false, false
+		case closed:
+			return "", nil, fmt.Errorf("ctag: malformed tag %q: unexpected %q after quoted value at offset %d", tagStr, c, i)
+		case c == '\'' && start:
+			end, ok := readQuoted(tagStr, i, &sb)
+			if !ok {
+				sb.WriteByte(c)
+				start = false
+				continue
+			}
+			i = end
+			start, closed = false, true
+		case c == '=' && !keyed:
+			sb.WriteByte(c)
+			start, keyed = true, true
+		default:
+			sb.WriteByte(c)
+			start = false
+		}
+	}
+	items = append(items, sb.String())
+
+	if len(items) > 1 {
+		return items[0], items[1:], nil
+	}
+	return items[0], nil, nil
+}
+
+// readQuoted writes the contents of the quoted value starting at tagStr[open] to sb,
+// resolving backslash escapes, and returns the offset of the closing quote. If the value is
+// never closed, nothing is written and false is returned.
+func readQuoted(tagStr string, open int, sb *strings.Builder) (int, bool) {
+	var value strings.Builder
+	for i := open + 1; i < len(tagStr); i++ {
+		switch c := tagStr[i]; c {
+		case '\\':
+			if i+1 == len(tagStr) {
+				return 0, false
+			}
+			i++
+			value.WriteByte(tagStr[i])
+		case '\'':
+			sb.WriteString(value.String())
+			return i, true
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return 0, false
+}
+
+// parse splits a raw tag value into its name and options on every comma.
+func parse(tagStr string) (string, []string) {
+	parts := strings.SplitN(tagStr, ",", 2)
+	if len(parts) > 1 {
+		return parts[0], strings.Split(parts[1], ",")
+	}
+	return parts[0], nil
+}
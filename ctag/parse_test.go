@@ -0,0 +1,164 @@
+package ctag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		name            string
+		tag             string
+		expectedName    string
+		expectedOptions []string
+		expectError     bool
+		errorMsg        string
+	}{
+		{
+			name:         "name only",
+			tag:          "id",
+			expectedName: "id",
+		},
+		{
+			name:            "unquoted options",
+			tag:             "text,comma,omitempty",
+			expectedName:    "text",
+			expectedOptions: []string{"comma", "omitempty"},
+		},
+		{
+			name:            "empty name",
+			tag:             ",omitempty",
+			expectedName:    "",
+			expectedOptions: []string{"omitempty"},
+		},
+		{
+			name:            "quoted option value",
+			tag:             "tags,sep=','",
+			expectedName:    "tags",
+			expectedOptions: []string{"sep=,"},
+		},
+		{
+			name:            "quoted value with spaces",
+			tag:             "desc,text='a, b'",
+			expectedName:    "desc",
+			expectedOptions: []string{"text=a, b"},
+		},
+		{
+			name:            "quoted name",
+			tag:             "'a,b',omitempty",
+			expectedName:    "a,b",
+			expectedOptions: []string{"omitempty"},
+		},
+		{
+			name:            "quoted whole option",
+			tag:             "name,'x,y'",
+			expectedName:    "name",
+			expectedOptions: []string{"x,y"},
+		},
+		{
+			name:            "escaped quote",
+			tag:             `desc,text='it\'s'`,
+			expectedName:    "desc",
+			expectedOptions: []string{"text=it's"},
+		},
+		{
+			name:            "escaped backslash",
+			tag:             `path,sep='\\'`,
+			expectedName:    "path",
+			expectedOptions: []string{`sep=\`},
+		},
+		{
+			name:            "empty quoted value",
+			tag:             "name,default=''",
+			expectedName:    "name",
+			expectedOptions: []string{"default="},
+		},
+		{
+			name:            "literal quote inside bare value",
+			tag:             "it's,don't",
+			expectedName:    "it's",
+			expectedOptions: []string{"don't"},
+		},
+		{
+			name:            "quote after second equals is literal",
+			tag:             "name,expr=a='b'",
+			expectedName:    "name",
+			expectedOptions: []string{"expr=a='b'"},
+		},
+		{
+			name:            "unterminated quote is literal",
+			tag:             "name,text='abc",
+			expectedName:    "name",
+			expectedOptions: []string{"text='abc"},
+		},
+		{
+			name:            "unterminated quote with commas is literal",
+			tag:             "'a,b,c",
+			expectedName:    "'a",
+			expectedOptions: []string{"b", "c"},
+		},
+		{
+			name:            "unterminated escape is literal",
+			tag:             `name,text='abc\`,
+			expectedName:    "name",
+			expectedOptions: []string{`text='abc\`},
+		},
+		{
+			name:        "trailing characters after quote",
+			tag:         "name,text='abc'def",
+			expectError: true,
+			errorMsg:    "after quoted value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, options, err := ParseTag(tt.tag)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedName, name)
+			assert.Equal(t, tt.expectedOptions, options)
+		})
+	}
+}
+
+func TestGetTagsQuotedOptions(t *testing.T) {
+	input := struct {
+		Tags []string `query:"tags,sep=','"`
+		Desc string   `doc:"desc,text='a, b'"`
+	}{
+		Tags: []string{"a", "b"},
+		Desc: "description",
+	}
+
+	tags, err := GetTags("query", input)
+	assertTags(t, CTags{{Key: "query", Name: "tags", Options: []string{"sep=,"}, Field: []string{"a", "b"}}}, tags, err)
+
+	tags, err = GetTags("doc", input)
+	assertTags(t, CTags{{Key: "doc", Name: "desc", Options: []string{"text=a, b"}, Field: "description"}}, tags, err)
+}
+
+func TestGetTagsMalformed(t *testing.T) {
+	input := struct {
+		Desc string `doc:"desc,text='a'b"`
+	}{}
+
+	tags, err := GetTags("doc", input)
+	assert.Nil(t, tags)
+	assert.ErrorContains(t, err, "malformed tag")
+}
+
+func TestGetTagsUnterminatedQuote(t *testing.T) {
+	input := struct {
+		Desc string `doc:"desc,text='a, b"`
+	}{}
+
+	tags, err := GetTags("doc", input)
+	assertTags(t, CTags{{Key: "doc", Name: "desc", Options: []string{"text='a", " b"}, Field: ""}}, tags, err)
+}
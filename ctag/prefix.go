@@ -0,0 +1,73 @@
+package ctag
+
+import "strings"
+
+// prefixAt returns the name prefix for key index k, or an empty prefix if prefixes is nil.
+func prefixAt(prefixes []string, k int) string {
+	if prefixes == nil {
+		return ""
+	}
+	return prefixes[k]
+}
+
+// qualifies reports whether the struct field described by fm prefixes the names of the fields
+// nested beneath it with its own name. Only names qualified with WithQualifiedNames are
+// prefixed, by fields that are neither embedded nor inline or whose tag has the "prefix" option.
+func (w *walker) qualifies(fm fieldMeta) bool {
+	return w.qualify && (fm.prefix || !w.flatten(fm))
+}
+
+// childPrefixes returns the name prefixes for the fields nested beneath field i for every
+// key marked in active. The prefixes are returned as is if no key extends them.
+func (w *walker) childPrefixes(metas []*typeMeta, i int, active []bool, prefixes []string) []string {
+	out, shared := prefixes, true
+	for k := range active {
+		if !active[k] {
+			continue
+		}
+		fm := metas[k].fields[i]
+		if !w.qualifies(fm) {
+			continue
+		}
+		name, ok := w.tagName(fm)
+		if !ok || name == "" {
+			continue
+		}
+		if shared {
+			out, shared = make([]string, len(w.keys)), false
+			copy(out, prefixes)
+		}
+		out[k] = w.join(out[k], name)
+	}
+	return out
+}
+
+// elemPrefixes returns the name prefixes for the fields of a struct held in a collection,
+// extending each non-empty prefix with the element's index or map key so that the names of
+// different elements stay distinct. Empty prefixes are left as they are.
+func (w *walker) elemPrefixes(prefixes []string, elem string) []string {
+	var out []string
+	for k, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+		if out == nil {
+			out = make([]string, len(prefixes))
+			copy(out, prefixes)
+		}
+		out[k] = w.join(prefix, elem)
+	}
+	if out == nil {
+		return prefixes
+	}
+	return out
+}
+
+// join appends name and the joiner set with WithNameJoiner to prefix. The joiner is not
+// repeated if name already ends with it, so that a tag such as `env:"DB_"` can spell it out.
+func (w *walker) join(prefix string, name string) string {
+	if strings.HasSuffix(name, w.joiner) {
+		return prefix + name
+	}
+	return prefix + name + w.joiner
+}
@@ -0,0 +1,186 @@
+package ctag
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type prefixPool struct {
+	Max int `env:"MAX"`
+}
+
+type prefixDB struct {
+	Host string     `env:"HOST"`
+	Pool prefixPool `env:"POOL"`
+}
+
+type prefixAudit struct {
+	User string `env:"USER"`
+}
+
+type prefixLog struct {
+	Level string `env:"LOG_LEVEL"`
+}
+
+type prefixConfig struct {
+	prefixAudit `env:"AUDIT,prefix"`
+	Name        string     `env:"NAME"`
+	DB          prefixDB   `env:"DB_"`
+	Cache       *prefixDB  `env:"CACHE,prefix"`
+	Log         prefixLog  `env:",inline"`
+	Replicas    []prefixDB `env:"REPLICA"`
+}
+
+func TestGetQualifiedNames(t *testing.T) {
+	input := prefixConfig{
+		Cache:    &prefixDB{},
+		Replicas: []prefixDB{{}, {}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{
+			name: "unqualified by default",
+			expected: []string{
+				"NAME",
+				"DB_", "HOST", "POOL", "MAX",
+				"CACHE", "HOST", "POOL", "MAX",
+				"", "LOG_LEVEL",
+				"REPLICA",
+				"USER",
+			},
+		},
+		{
+			name: "joiner without qualified names",
+			opts: []Option{WithNameJoiner(".")},
+			expected: []string{
+				"NAME",
+				"DB_", "HOST", "POOL", "MAX",
+				"CACHE", "HOST", "POOL", "MAX",
+				"", "LOG_LEVEL",
+				"REPLICA",
+				"USER",
+			},
+		},
+		{
+			name: "qualified with default joiner",
+			opts: []Option{WithQualifiedNames(true), WithCollections(true)},
+			expected: []string{
+				"NAME",
+				"DB_", "DB_HOST", "DB_POOL", "DB_POOL_MAX",
+				"CACHE", "CACHE_HOST", "CACHE_POOL", "CACHE_POOL_MAX",
+				"LOG_LEVEL",
+				"REPLICA",
+				"REPLICA_0_HOST", "REPLICA_0_POOL", "REPLICA_0_POOL_MAX",
+				"REPLICA_1_HOST", "REPLICA_1_POOL", "REPLICA_1_POOL_MAX",
+				"AUDIT_USER",
+			},
+		},
+		{
+			name: "qualified with empty joiner",
+			opts: []Option{WithQualifiedNames(true), WithNameJoiner("")},
+			expected: []string{
+				"NAME",
+				"DB_", "DB_HOST", "DB_POOL", "DB_POOLMAX",
+				"CACHE", "CACHEHOST", "CACHEPOOL", "CACHEPOOLMAX",
+				"LOG_LEVEL",
+				"REPLICA",
+				"AUDITUSER",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := Get("env", &input, tt.opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tagNames(tags))
+		})
+	}
+}
+
+func TestGetQualifiedNamesUntagged(t *testing.T) {
+	type Server struct {
+		ReadTimeout string
+	}
+	type Config struct {
+		HTTPServer Server
+		Admin      Server `flag:"admin"`
+	}
+
+	tags, err := Get("flag", Config{}, WithUntaggedNaming(KebabCase), WithQualifiedNames(true), WithNameJoiner("."))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http-server", "http-server.read-timeout", "admin", "admin.read-timeout"}, tagNames(tags))
+}
+
+func TestGetQualifiedNamesProcess(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST"`
+	}
+	type Config struct {
+		Primary DB `env:"PRIMARY,prefix"`
+		Replica DB `env:"REPLICA,prefix"`
+	}
+
+	var config Config
+	p := &mapProcessor{values: map[string]string{"PRIMARY_HOST": "db1", "REPLICA_HOST": "db2"}}
+	_, err := Get("env", &config, WithProcessor(p), WithQualifiedNames(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "db1", config.Primary.Host)
+	assert.Equal(t, "db2", config.Replica.Host)
+}
+
+func TestGetMultiQualifiedNames(t *testing.T) {
+	type DB struct {
+		Host string `env:"HOST" flag:"host"`
+	}
+	type Config struct {
+		DB `env:"DB,prefix" flag:"db"`
+	}
+
+	tags, err := GetMulti([]string{"env", "flag"}, Config{}, WithQualifiedNames(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DB_HOST"}, tagNames(tags["env"]))
+	assert.Equal(t, []string{"host"}, tagNames(tags["flag"]))
+}
+
+func TestGetQualifiedNamesEmbedPromote(t *testing.T) {
+	type Base struct {
+		Host string `env:"HOST"`
+	}
+	type Config struct {
+		Base `env:"BASE"`
+		Host string    `env:"HOST"`
+		Log  prefixLog `env:",inline"`
+	}
+
+	tags, err := Get("env", Config{}, WithEmbedMode(EmbedPromote), WithQualifiedNames(true), WithNameJoiner("_"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"BASE", "BASE_HOST", "HOST", "LOG_LEVEL"}, tagNames(tags))
+}
+
+func TestGetInlineAndPrefixUnqualified(t *testing.T) {
+	type Inner struct {
+		A string `x:"a"`
+	}
+	type Server struct {
+		Port int `x:"port"`
+	}
+	type Outer struct {
+		Inner  Inner  `x:",inline"`
+		B      string `x:"b"`
+		Server Server `x:"server,prefix"`
+	}
+
+	tags, err := GetTags("x", Outer{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "a", "b", "server", "port"}, tagNames(tags))
+
+	tags, err = Get("x", Outer{}, WithQualifiedNames(true))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "server", "server_port"}, tagNames(tags))
+}
@@ -0,0 +1,200 @@
+package ctag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// builtinRules are the rules registered in every Validator returned by NewValidator.
+var builtinRules = map[string]Rule{
+	"required":    RuleFunc(ruleRequired),
+	"required_if": RuleFunc(ruleRequiredIf),
+	"min":         RuleFunc(ruleMin),
+	"max":         RuleFunc(ruleMax),
+	"len":         RuleFunc(ruleLen),
+	"oneof":       RuleFunc(ruleOneOf),
+	"regex":       RuleFunc(ruleRegex),
+	"eqfield":     RuleFunc(ruleEqField),
+	"nefield":     RuleFunc(ruleNeField),
+}
+
+// patterns caches the regular expressions compiled for the regex rule, keyed by pattern.
+var patterns sync.Map
+
+// ruleRequired rejects fields holding their zero value. A non-nil pointer is set even if it
+// points to a zero value.
+func ruleRequired(ctx *RuleContext) error {
+	if !ctx.value.IsValid() || ctx.value.IsZero() {
+		return errors.New("is required")
+	}
+	return nil
+}
+
+// ruleRequiredIf applies ruleRequired if every field named in the parameter, formatted with
+// fmt.Sprint, equals the value following it, as in "Status active Kind user".
+func ruleRequiredIf(ctx *RuleContext) error {
+	pairs := strings.Fields(ctx.Param)
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return fmt.Errorf("invalid parameter %q, want field and value pairs", ctx.Param)
+	}
+	conds := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		other, ok := ctx.FieldByName(pairs[i])
+		if !ok {
+			return fmt.Errorf("%w %q", ErrUnknownField, pairs[i])
+		}
+		if other == nil || fmt.Sprint(other) != pairs[i+1] {
+			return nil
+		}
+		conds = append(conds, pairs[i]+" is "+pairs[i+1])
+	}
+	if err := ruleRequired(ctx); err != nil {
+		return fmt.Errorf("is required when %s", strings.Join(conds, " and "))
+	}
+	return nil
+}
+
+// ruleMin rejects numbers below the parameter and lengths shorter than it.
+func ruleMin(ctx *RuleContext) error {
+	return compare(ctx, func(n, limit float64) bool { return n >= limit }, "at least")
+}
+
+// ruleMax rejects numbers above the parameter and lengths longer than it.
+func ruleMax(ctx *RuleContext) error {
+	return compare(ctx, func(n, limit float64) bool { return n <= limit }, "at most")
+}
+
+// ruleLen rejects numbers other than the parameter and lengths other than it.
+func ruleLen(ctx *RuleContext) error {
+	return compare(ctx, func(n, limit float64) bool { return n == limit }, "exactly")
+}
+
+// compare measures the field, a number or the length of a string, slice, array or map, and
+// returns an error phrased with relation unless ok accepts it against the parameter.
+// Nil pointers pass.
+func compare(ctx *RuleContext, ok func(n, limit float64) bool, relation string) error {
+	if ctx.Field == nil {
+		return nil
+	}
+	v := reflect.ValueOf(ctx.Field)
+	n, isLen, err := measure(v)
+	if err != nil {
+		return err
+	}
+	limit, err := parseLimit(v.Type(), ctx.Param)
+	if err != nil {
+		return err
+	}
+	if ok(n, limit) {
+		return nil
+	}
+	if isLen {
+		return fmt.Errorf("length must be %s %s", relation, ctx.Param)
+	}
+	return fmt.Errorf("must be %s %s", relation, ctx.Param)
+}
+
+// measure returns the number held by v, or the length of v and true if v is a string,
+// counted in characters, or a slice, array or map.
+func measure(v reflect.Value) (float64, bool, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true, nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), false, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), false, nil
+	}
+	return 0, false, fmt.Errorf("cannot measure %v", v.Type())
+}
+
+// parseLimit parses the parameter of a comparison rule for a field of type t, as a
+// duration for time.Duration fields and as a number otherwise.
+func parseLimit(t reflect.Type, param string) (float64, error) {
+	if t == durationType {
+		if d, err := time.ParseDuration(param); err == nil {
+			return float64(d), nil
+		}
+	}
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid parameter %q", param)
+	}
+	return limit, nil
+}
+
+// ruleOneOf rejects fields that, formatted with fmt.Sprint, are none of the space-separated
+// values of the parameter. Nil pointers pass.
+func ruleOneOf(ctx *RuleContext) error {
+	if ctx.Field == nil {
+		return nil
+	}
+	values := strings.Fields(ctx.Param)
+	s := fmt.Sprint(ctx.Field)
+	for _, value := range values {
+		if s == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(values, ", "))
+}
+
+// ruleRegex rejects strings that do not match the regular expression of the parameter.
+// Nil pointers pass.
+func ruleRegex(ctx *RuleContext) error {
+	if ctx.Field == nil {
+		return nil
+	}
+	v := reflect.ValueOf(ctx.Field)
+	if v.Kind() != reflect.String {
+		return fmt.Errorf("cannot match %v", v.Type())
+	}
+
+	re, ok := patterns.Load(ctx.Param)
+	if !ok {
+		compiled, err := regexp.Compile(ctx.Param)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		re, _ = patterns.LoadOrStore(ctx.Param, compiled)
+	}
+	if !re.(*regexp.Regexp).MatchString(v.String()) {
+		return fmt.Errorf("must match %s", ctx.Param)
+	}
+	return nil
+}
+
+// ruleEqField rejects fields that differ from the field named by the parameter.
+func ruleEqField(ctx *RuleContext) error {
+	other, ok := ctx.FieldByName(ctx.Param)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownField, ctx.Param)
+	}
+	if !reflect.DeepEqual(ctx.Field, other) {
+		return fmt.Errorf("must equal %s", ctx.Param)
+	}
+	return nil
+}
+
+// ruleNeField rejects fields that equal the field named by the parameter.
+func ruleNeField(ctx *RuleContext) error {
+	other, ok := ctx.FieldByName(ctx.Param)
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownField, ctx.Param)
+	}
+	if reflect.DeepEqual(ctx.Field, other) {
+		return fmt.Errorf("must not equal %s", ctx.Param)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package ctag
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinRules(t *testing.T) {
+	type Ranges struct {
+		Name    string            `validate:"min=2,max=4"`
+		Runes   string            `validate:"len=3"`
+		Ratio   float64           `validate:"min=0,max=1"`
+		Size    uint8             `validate:"max=10"`
+		Tags    []string          `validate:"max=2"`
+		Labels  map[string]string `validate:"min=1"`
+		Timeout time.Duration     `validate:"min=1s,max=1m"`
+		Limit   *int              `validate:"min=1"`
+		Level   int               `validate:"oneof=1 2 3"`
+	}
+
+	type Cross struct {
+		Kind     string
+		Status   string
+		Owner    *string `validate:"required_if=Kind user Status active"`
+		Password string
+		Old      string  `validate:"nefield=Password"`
+		Confirm  *string `validate:"eqfield=Password"`
+	}
+
+	type Misconfigured struct {
+		Count   int    `validate:"min=many"`
+		Name    int    `validate:"regex=^a"`
+		Pattern string `validate:"regex=["`
+		Pairs   string `validate:"required_if=Kind"`
+		Flag    bool   `validate:"max=1"`
+	}
+
+	tests := []struct {
+		name     string
+		data     any
+		expected string
+	}{
+		{
+			name: "valid ranges",
+			data: Ranges{Name: "abc", Runes: "äöü", Ratio: 0.5, Size: 10, Tags: []string{"a"}, Labels: map[string]string{"a": "b"}, Timeout: time.Second, Level: 2},
+		},
+		{
+			name: "invalid ranges",
+			data: Ranges{Name: "abcde", Runes: "ab", Ratio: 1.5, Size: 11, Tags: []string{"a", "b", "c"}, Timeout: time.Hour, Limit: ptr(0), Level: 4},
+			expected: "ctag: field Name failed max=4: length must be at most 4\n" +
+				"ctag: field Runes failed len=3: length must be exactly 3\n" +
+				"ctag: field Ratio failed max=1: must be at most 1\n" +
+				"ctag: field Size failed max=10: must be at most 10\n" +
+				"ctag: field Tags failed max=2: length must be at most 2\n" +
+				"ctag: field Labels failed min=1: length must be at least 1\n" +
+				"ctag: field Timeout failed max=1m: must be at most 1m\n" +
+				"ctag: field Limit failed min=1: must be at least 1\n" +
+				"ctag: field Level failed oneof=1 2 3: must be one of 1, 2, 3",
+		},
+		{
+			name: "cross-field rules satisfied",
+			data: Cross{Kind: "user", Status: "active", Owner: ptr(""), Password: "new", Old: "old", Confirm: ptr("new")},
+		},
+		{
+			name:     "required_if condition not met",
+			data:     Cross{Kind: "user", Status: "inactive", Password: "new"},
+			expected: "ctag: field Confirm failed eqfield=Password: must equal Password",
+		},
+		{
+			name: "cross-field rules violated",
+			data: Cross{Kind: "user", Status: "active", Password: "same", Old: "same", Confirm: ptr("other")},
+			expected: "ctag: field Owner failed required_if=Kind user Status active: is required when Kind is user and Status is active\n" +
+				"ctag: field Old failed nefield=Password: must not equal Password\n" +
+				"ctag: field Confirm failed eqfield=Password: must equal Password",
+		},
+		{
+			name: "misconfigured rules",
+			data: Misconfigured{},
+			expected: `ctag: field Count failed min=many: invalid parameter "many"` + "\n" +
+				"ctag: field Name failed regex=^a: cannot match int\n" +
+				"ctag: field Pattern failed regex=[: invalid pattern: error parsing regexp: missing closing ]: `[`\n" +
+				`ctag: field Pairs failed required_if=Kind: invalid parameter "Kind", want field and value pairs` + "\n" +
+				"ctag: field Flag failed max=1: cannot measure bool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.data)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestCrossFieldRulesUnknownField(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     any
+		expected string
+	}{
+		{
+			name: "eqfield",
+			data: struct {
+				Password string
+				Confirm  string `validate:"eqfield=Passwrd"`
+			}{Password: "secret"},
+			expected: `ctag: field Confirm (validate:"eqfield=Passwrd"): ctag: unknown field "Passwrd"`,
+		},
+		{
+			name: "nefield",
+			data: struct {
+				Old string `validate:"nefield=New"`
+			}{},
+			expected: `ctag: field Old (validate:"nefield=New"): ctag: unknown field "New"`,
+		},
+		{
+			name: "required_if",
+			data: struct {
+				Kind  string
+				Owner string `validate:"required_if=Kind user Stat active"`
+			}{Kind: "user"},
+			expected: `ctag: field Owner (validate:"required_if=Kind user Stat active"): ctag: unknown field "Stat"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.data)
+			assert.EqualError(t, err, tt.expected)
+			assert.ErrorIs(t, err, ErrUnknownField)
+
+			var fieldErrs FieldErrors
+			assert.True(t, errors.As(err, &fieldErrs))
+			var validationErrs ValidationErrors
+			assert.False(t, errors.As(err, &validationErrs))
+		})
+	}
+}
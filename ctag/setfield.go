@@ -0,0 +1,669 @@
+package ctag
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// bytesType is the type of []byte, which is treated as a single value rather than a slice of elements.
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// SetField sets the value pointed to by field to value, converting it to the field's type.
+// It is intended for use in TagProcessor implementations, which receive settable fields as pointers.
+//
+// Strings are parsed into numbers, booleans and slices, numbers are converted between numeric
+// types, rejecting values that are out of range or would lose a fractional part unless
+// WithLenientNumbers is set, and maps with string keys populate structs. Maps are populated from
+// "k=v,k2=v2" strings, from other maps with their keys and values converted, and from structs.
+// Slice elements and map entries are separated by commas, or the separators set with
+// WithSeparators or WithTag, and may be double-quoted as in CSV to contain a separator. Arrays are populated like slices,
+// but only from values with exactly as many elements. Strings set byte slices and arrays like
+// any other slice, as in "1,2,3", unless an encoding is set with WithTag or WithEncoding, such as
+// EncodingHex, or EncodingRaw to use the bytes of the string as they are.
+//
+// A Converter registered for the source and target types, with WithConverters or in
+// DefaultConverters, takes precedence over every other conversion. Strings are parsed into
+// time.Duration with time.ParseDuration, and into time.Time with the layout set with WithTag or
+// WithTimeLayout, RFC3339 by default, while numbers are converted to time.Time as Unix
+// timestamps. Types implementing encoding.TextUnmarshaler, flag.Value, sql.Scanner or
+// json.Unmarshaler are then populated through those interfaces before the built-in conversions.
+//
+// Parameters:
+//
+//	field - a non-nil pointer to the value to set
+//	value - the value to convert and store, or nil to store the zero value
+//	opts  - options configuring the conversion, such as WithConverters
+//
+// Returns:
+//
+//	ErrNotPointer, ErrNilPointer or ErrNotSettable if field cannot be set, or a *ConversionError if value cannot be converted.
+//
+// Example usage:
+//
+//	var addr netip.Addr
+//	err := SetField(&addr, "192.168.0.1")
+func SetField(field any, value any, opts ...SetOption) error {
+	fieldVal := reflect.ValueOf(field)
+	if fieldVal.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T", ErrNotPointer, field)
+	}
+	if fieldVal.IsNil() {
+		return ErrNilPointer
+	}
+
+	fieldElem := fieldVal.Elem()
+	if !fieldElem.CanSet() {
+		return ErrNotSettable
+	}
+
+	s := newSetter(opts)
+	return s.setValue(fieldElem, value)
+}
+
+// setter holds the options of a single SetField call and converts values with them.
+type setter struct {
+	setOptions
+}
+
+// newSetter returns a setter with opts applied.
+func newSetter(opts []SetOption) *setter {
+	s := &setter{}
+	for _, opt := range opts {
+		opt(&s.setOptions)
+	}
+	return s
+}
+
+func (s *setter) setValue(fieldVal reflect.Value, value any) error {
+	if value == nil {
+		fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		return nil
+	}
+
+	if ok, err := s.setConverted(fieldVal, value); ok {
+		return err
+	}
+
+	if ok, err := s.setTime(fieldVal, value); ok {
+		return err
+	}
+
+	valueVal := reflect.ValueOf(value)
+	fieldType := fieldVal.Type()
+
+	if valueVal.Type().AssignableTo(fieldType) {
+		fieldVal.Set(valueVal)
+		return nil
+	}
+
+	if ok, err := setUnmarshaler(fieldVal, value); ok {
+		return err
+	}
+
+	switch fieldType.Kind() {
+	case reflect.Ptr:
+		return s.setPointerValue(fieldVal, value)
+	case reflect.Interface:
+		fieldVal.Set(valueVal)
+		return nil
+	case reflect.Struct:
+		return s.setStructValue(fieldVal, value)
+	case reflect.Slice:
+		return s.setSliceValue(fieldVal, value)
+	case reflect.Array:
+		return s.setArrayValue(fieldVal, value)
+	case reflect.Map:
+		return s.setMapValue(fieldVal, value)
+	case reflect.String:
+		fieldVal.SetString(fmt.Sprintf("%v", value))
+		return nil
+	}
+
+	if valueVal.Kind() == reflect.String {
+		return s.setFromString(fieldVal, valueVal.String())
+	}
+
+	if isNumeric(valueVal.Kind()) && isNumeric(fieldType.Kind()) {
+		return s.setNumericValue(fieldVal, valueVal)
+	}
+
+	return &ConversionError{Value: value, Type: fieldType}
+}
+
+func (s *setter) setPointerValue(fieldVal reflect.Value, value any) error {
+	if fieldVal.IsNil() {
+		fieldVal.Set(reflect.New(fieldVal.Type().Elem()))
+	}
+	return s.setValue(fieldVal.Elem(), value)
+}
+
+func (s *setter) setSliceValue(fieldVal reflect.Value, value any) error {
+	valueVal := reflect.ValueOf(value)
+
+	if valueVal.Type().AssignableTo(fieldVal.Type()) {
+		fieldVal.Set(valueVal)
+		return nil
+	}
+
+	switch valueVal.Kind() {
+	case reflect.String:
+		if fieldVal.Type().Elem().Kind() == reflect.Uint8 && s.encoding != "" {
+			return s.setBytesFromString(fieldVal, valueVal.String())
+		}
+		return s.setSliceFromString(fieldVal, valueVal.String())
+	case reflect.Slice, reflect.Array:
+		return s.setSliceFromSlice(fieldVal, valueVal)
+	}
+
+	if valueVal.Type().AssignableTo(fieldVal.Type().Elem()) {
+		slice := reflect.MakeSlice(fieldVal.Type(), 1, 1)
+		slice.Index(0).Set(valueVal)
+		fieldVal.Set(slice)
+		return nil
+	}
+
+	return &ConversionError{Value: value, Type: fieldVal.Type()}
+}
+
+// setBytesFromString sets the byte slice fieldVal from str, decoded with the encoding set
+// with WithEncoding or WithTag. With EncodingRaw, the bytes of str are used as they are.
+func (s *setter) setBytesFromString(fieldVal reflect.Value, str string) error {
+	b, err := s.decodeBytes(str)
+	if err != nil {
+		return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+	}
+	fieldVal.Set(reflect.ValueOf(b).Convert(fieldVal.Type()))
+	return nil
+}
+
+// decodeBytes decodes str with the encoding set with WithEncoding or WithTag. Base64 input
+// is accepted with or without padding.
+func (s *setter) decodeBytes(str string) ([]byte, error) {
+	switch s.encoding {
+	case EncodingRaw:
+		return []byte(str), nil
+	case EncodingBase64:
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(str, "="))
+	case EncodingBase64URL:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(str, "="))
+	case EncodingHex:
+		return hex.DecodeString(str)
+	}
+	return nil, fmt.Errorf("unknown encoding %q", s.encoding)
+}
+
+// setArrayValue sets the array fieldVal from value by converting value to a slice of the
+// array's element type, as setSliceValue does, and copying it into the array. The slice must
+// have exactly the length of the array.
+func (s *setter) setArrayValue(fieldVal reflect.Value, value any) error {
+	slice := reflect.New(reflect.SliceOf(fieldVal.Type().Elem())).Elem()
+	if err := s.setSliceValue(slice, value); err != nil {
+		var convErr *ConversionError
+		if errors.As(err, &convErr) && convErr.Type == slice.Type() {
+			convErr.Type = fieldVal.Type()
+		}
+		return err
+	}
+
+	if slice.Len() != fieldVal.Len() {
+		return &ConversionError{
+			Value: value,
+			Type:  fieldVal.Type(),
+			Err:   fmt.Errorf("got %d elements, want %d", slice.Len(), fieldVal.Len()),
+		}
+	}
+	reflect.Copy(fieldVal, slice)
+	return nil
+}
+
+// setSliceFromString sets the slice fieldVal from the elements of str, split on the separator
+// for the slice's nesting depth. Elements of nested slices are split again with the separator
+// of the next level, and quotes are removed from elements only once they are no longer split.
+func (s *setter) setSliceFromString(fieldVal reflect.Value, str string) error {
+	if str == "" {
+		fieldVal.Set(reflect.MakeSlice(fieldVal.Type(), 0, 0))
+		return nil
+	}
+
+	depth := s.listDepth(fieldVal.Type())
+	sep, err := s.separator(depth)
+	if err != nil {
+		return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+	}
+	parts, err := splitList(str, sep)
+	if err != nil {
+		return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+	}
+	slice := reflect.MakeSlice(fieldVal.Type(), len(parts), len(parts))
+
+	for i, part := range parts {
+		if depth == 1 {
+			part = unquote(part)
+		}
+		if err := s.setValue(slice.Index(i), part); err != nil {
+			return fmt.Errorf("ctag: error converting slice element %d: %w", i, err)
+		}
+	}
+
+	fieldVal.Set(slice)
+	return nil
+}
+
+// setFromString sets the scalar fieldVal from str, parsing numbers and booleans with the
+// literals accepted in the ParseMode set with WithParseMode or WithTag.
+func (s *setter) setFromString(fieldVal reflect.Value, str string) error {
+	switch fieldVal.Kind() {
+	case reflect.String:
+		fieldVal.SetString(str)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s.parseMode == ParseExtended {
+			return s.setNumberLiteral(fieldVal, str)
+		}
+		val, err := strconv.ParseInt(str, 10, s.bitSize(fieldVal))
+		if err != nil {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.SetInt(val)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s.parseMode == ParseExtended {
+			return s.setNumberLiteral(fieldVal, str)
+		}
+		val, err := strconv.ParseUint(str, 10, s.bitSize(fieldVal))
+		if err != nil {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.SetUint(val)
+	case reflect.Float32, reflect.Float64:
+		if s.parseMode == ParseExtended {
+			return s.setNumberLiteral(fieldVal, str)
+		}
+		val, err := strconv.ParseFloat(str, s.bitSize(fieldVal))
+		if err != nil {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.SetFloat(val)
+	case reflect.Bool:
+		val, err := s.parseBool(str)
+		if err != nil {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.SetBool(val)
+	default:
+		return &ConversionError{Value: str, Type: fieldVal.Type()}
+	}
+	return nil
+}
+
+func (s *setter) setNumericValue(fieldVal reflect.Value, valueVal reflect.Value) error {
+	var err error
+	switch fieldVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch valueVal.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = valueVal.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if valueVal.Uint() > math.MaxInt64 {
+				err = strconv.ErrRange
+			}
+			n = int64(valueVal.Uint())
+		case reflect.Float32, reflect.Float64:
+			err = checkFloatToInt(valueVal.Float(), -(1 << 63), 1<<63)
+			n = int64(valueVal.Float())
+		default:
+			return &ConversionError{Value: valueVal.Interface(), Type: fieldVal.Type()}
+		}
+		if err == nil && fieldVal.OverflowInt(n) {
+			err = strconv.ErrRange
+		}
+		if err == nil || s.lenient {
+			fieldVal.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch valueVal.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if valueVal.Int() < 0 {
+				err = strconv.ErrRange
+			}
+			n = uint64(valueVal.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = valueVal.Uint()
+		case reflect.Float32, reflect.Float64:
+			err = checkFloatToInt(valueVal.Float(), 0, 1<<64)
+			n = uint64(valueVal.Float())
+		default:
+			return &ConversionError{Value: valueVal.Interface(), Type: fieldVal.Type()}
+		}
+		if err == nil && fieldVal.OverflowUint(n) {
+			err = strconv.ErrRange
+		}
+		if err == nil || s.lenient {
+			fieldVal.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch valueVal.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f = float64(valueVal.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			f = float64(valueVal.Uint())
+		case reflect.Float32, reflect.Float64:
+			f = valueVal.Float()
+		default:
+			return &ConversionError{Value: valueVal.Interface(), Type: fieldVal.Type()}
+		}
+		if fieldVal.OverflowFloat(f) {
+			err = strconv.ErrRange
+		}
+		if err == nil || s.lenient {
+			fieldVal.SetFloat(f)
+			return nil
+		}
+	default:
+		return &ConversionError{Value: valueVal.Interface(), Type: fieldVal.Type()}
+	}
+	return &ConversionError{Value: valueVal.Interface(), Type: fieldVal.Type(), Err: err}
+}
+
+// bitSize returns the bit size strings are parsed with for the numeric fieldVal: the size of
+// its type, so that out-of-range values are rejected, or 64 with WithLenientNumbers.
+func (s *setter) bitSize(fieldVal reflect.Value) int {
+	if s.lenient {
+		return 64
+	}
+	return fieldVal.Type().Bits()
+}
+
+// checkFloatToInt returns an error if converting f to an integer would lose its fractional
+// part, if f lies outside the range [min, max) of the integer type, or if f is NaN.
+func checkFloatToInt(f float64, min float64, max float64) error {
+	if math.IsNaN(f) {
+		return fmt.Errorf("%v is not a number", f)
+	}
+	if math.Trunc(f) != f {
+		return fmt.Errorf("%v has a fractional part", f)
+	}
+	if f < min || f >= max {
+		return strconv.ErrRange
+	}
+	return nil
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func (s *setter) setStructValue(fieldVal reflect.Value, value any) error {
+	valueVal := reflect.ValueOf(value)
+
+	if valueVal.Type().AssignableTo(fieldVal.Type()) {
+		fieldVal.Set(valueVal)
+		return nil
+	}
+
+	if valueVal.Kind() == reflect.Map && valueVal.Type().Key().Kind() == reflect.String {
+		return s.setStructFromMap(fieldVal, valueVal)
+	}
+
+	return &ConversionError{Value: value, Type: fieldVal.Type()}
+}
+
+func (s *setter) setStructFromMap(structVal reflect.Value, mapVal reflect.Value) error {
+	structType := structVal.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldVal := structVal.Field(i)
+
+		if field.PkgPath != "" || !fieldVal.CanSet() {
+			continue
+		}
+
+		tagName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		var mapValue interface{}
+		var found bool
+
+		if mapKey := reflect.ValueOf(tagName); mapVal.MapIndex(mapKey).IsValid() {
+			mapValue = mapVal.MapIndex(mapKey).Interface()
+			found = true
+		} else if tagName != field.Name {
+			if fieldKey := reflect.ValueOf(field.Name); mapVal.MapIndex(fieldKey).IsValid() {
+				mapValue = mapVal.MapIndex(fieldKey).Interface()
+				found = true
+			}
+		}
+
+		if found {
+			if err := s.setValue(fieldVal, mapValue); err != nil {
+				return &FieldError{Path: field.Name, Key: "json", Name: tagName, Err: err}
+			}
+		}
+	}
+
+	return nil
+}
+
+// setMapValue sets the map fieldVal from value, building a new map whose keys and values
+// are converted to the map's key and element types:
+//
+//   - A string is parsed as key=value pairs separated by commas, or the innermost separator set
+//     with WithSeparators or WithTag. Keys and values may be double-quoted, as slice elements
+//     may, to contain the separator or '='. When the element type is a slice, as in url.Values,
+//     the values of repeated keys are appended.
+//   - A map is converted entry by entry. A slice value, such as the values of a url.Values or
+//     http.Header, is converted by its single element when the element type is not a slice. An
+//     empty slice gives the zero value, keeping the key, and a slice of several values is an error.
+//   - A struct, or a pointer to one, is converted field by field, keyed by the names jsonFieldName returns.
+func (s *setter) setMapValue(fieldVal reflect.Value, value any) error {
+	valueVal := reflect.ValueOf(value)
+	if valueVal.Kind() == reflect.Ptr && valueVal.Type().Elem().Kind() == reflect.Struct {
+		if valueVal.IsNil() {
+			fieldVal.Set(reflect.Zero(fieldVal.Type()))
+			return nil
+		}
+		valueVal = valueVal.Elem()
+	}
+
+	switch valueVal.Kind() {
+	case reflect.String:
+		return s.setMapFromString(fieldVal, valueVal.String())
+	case reflect.Map:
+		return s.setMapFromMap(fieldVal, valueVal)
+	case reflect.Struct:
+		return s.setMapFromStruct(fieldVal, valueVal)
+	}
+
+	return &ConversionError{Value: value, Type: fieldVal.Type()}
+}
+
+func (s *setter) setMapFromString(fieldVal reflect.Value, str string) error {
+	m := reflect.MakeMap(fieldVal.Type())
+	if str == "" {
+		fieldVal.Set(m)
+		return nil
+	}
+
+	sep, err := s.separator(1)
+	if err != nil {
+		return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+	}
+	pairs, err := splitList(str, sep)
+	if err != nil {
+		return &ConversionError{Value: str, Type: fieldVal.Type(), Err: err}
+	}
+
+	appendRepeated := fieldVal.Type().Elem().Kind() == reflect.Slice
+	list := s.listDepth(fieldVal.Type().Elem()) > 0
+	for _, pair := range pairs {
+		k, v, ok := cutList(pair, "=")
+		if !ok {
+			return &ConversionError{Value: str, Type: fieldVal.Type(), Err: fmt.Errorf("missing \"=\" in %q", pair)}
+		}
+		if v = strings.TrimSpace(v); !list {
+			v = unquote(v)
+		}
+
+		key, err := s.mapKey(fieldVal.Type(), unquote(strings.TrimSpace(k)))
+		if err != nil {
+			return err
+		}
+		elem, err := s.mapElem(fieldVal.Type(), key, v)
+		if err != nil {
+			return err
+		}
+		if existing := m.MapIndex(key); appendRepeated && existing.IsValid() {
+			elem = reflect.AppendSlice(existing, elem)
+		}
+		m.SetMapIndex(key, elem)
+	}
+
+	fieldVal.Set(m)
+	return nil
+}
+
+func (s *setter) setMapFromMap(fieldVal reflect.Value, sourceMap reflect.Value) error {
+	if sourceMap.IsNil() {
+		fieldVal.Set(reflect.Zero(fieldVal.Type()))
+		return nil
+	}
+
+	single := fieldVal.Type().Elem().Kind() != reflect.Slice
+	m := reflect.MakeMapWithSize(fieldVal.Type(), sourceMap.Len())
+	for _, sourceKey := range sortedMapKeys(sourceMap) {
+		key, err := s.mapKey(fieldVal.Type(), sourceKey.Interface())
+		if err != nil {
+			return err
+		}
+
+		sourceElem := sourceMap.MapIndex(sourceKey)
+		for sourceElem.Kind() == reflect.Interface && !sourceElem.IsNil() {
+			sourceElem = sourceElem.Elem()
+		}
+		if single && sourceElem.Kind() == reflect.Slice && sourceElem.Type() != bytesType {
+			switch n := sourceElem.Len(); n {
+			case 0:
+				sourceElem = reflect.Value{}
+			case 1:
+				sourceElem = sourceElem.Index(0)
+			default:
+				err := &ConversionError{Value: sourceElem.Interface(), Type: fieldVal.Type().Elem(), Err: fmt.Errorf("%d values for a single value", n)}
+				return fmt.Errorf("ctag: error converting map value for key %s: %w", formatMapKey(key), err)
+			}
+		}
+
+		var elemValue any
+		if sourceElem.IsValid() {
+			elemValue = sourceElem.Interface()
+		}
+		elem, err := s.mapElem(fieldVal.Type(), key, elemValue)
+		if err != nil {
+			return err
+		}
+		m.SetMapIndex(key, elem)
+	}
+
+	fieldVal.Set(m)
+	return nil
+}
+
+func (s *setter) setMapFromStruct(fieldVal reflect.Value, structVal reflect.Value) error {
+	structType := structVal.Type()
+	m := reflect.MakeMap(fieldVal.Type())
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tagName, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		key, err := s.mapKey(fieldVal.Type(), tagName)
+		if err != nil {
+			return err
+		}
+		elem, err := s.mapElem(fieldVal.Type(), key, structVal.Field(i).Interface())
+		if err != nil {
+			return &FieldError{Path: field.Name, Key: "json", Name: tagName, Err: err}
+		}
+		m.SetMapIndex(key, elem)
+	}
+
+	fieldVal.Set(m)
+	return nil
+}
+
+// mapKey converts value to the key type of mapType.
+func (s *setter) mapKey(mapType reflect.Type, value any) (reflect.Value, error) {
+	key := reflect.New(mapType.Key()).Elem()
+	if err := s.setValue(key, value); err != nil {
+		return key, fmt.Errorf("ctag: error converting map key %v: %w", value, err)
+	}
+	return key, nil
+}
+
+// mapElem converts value to the element type of mapType, for the entry with the given key.
+func (s *setter) mapElem(mapType reflect.Type, key reflect.Value, value any) (reflect.Value, error) {
+	elem := reflect.New(mapType.Elem()).Elem()
+	if err := s.setValue(elem, value); err != nil {
+		return elem, fmt.Errorf("ctag: error converting map value for key %s: %w", formatMapKey(key), err)
+	}
+	return elem, nil
+}
+
+// jsonFieldName returns the name a struct field is read and written under when converting
+// between structs and maps: the name in its json tag, or the Go field name if the tag has none.
+// It reports false for fields tagged "-".
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	jsonTag := field.Tag.Get("json")
+	if jsonTag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(jsonTag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+func (s *setter) setSliceFromSlice(fieldVal reflect.Value, sourceSlice reflect.Value) error {
+	if sourceSlice.Len() == 0 {
+		fieldVal.Set(reflect.MakeSlice(fieldVal.Type(), 0, 0))
+		return nil
+	}
+
+	slice := reflect.MakeSlice(fieldVal.Type(), sourceSlice.Len(), sourceSlice.Len())
+
+	for i := 0; i < sourceSlice.Len(); i++ {
+		sourceElem := sourceSlice.Index(i).Interface()
+		targetElem := slice.Index(i)
+
+		if err := s.setValue(targetElem, sourceElem); err != nil {
+			return fmt.Errorf("ctag: error converting slice element %d: %w", i, err)
+		}
+	}
+
+	fieldVal.Set(slice)
+	return nil
+}
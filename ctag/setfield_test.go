@@ -0,0 +1,571 @@
+package ctag
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setFieldCase is a SetField test case. The field is built by a function so that each run
+// starts from a fresh zero value.
+type setFieldCase struct {
+	name     string
+	field    func() any
+	value    any
+	opts     []SetOption
+	expected any
+	errMsg   string
+}
+
+// runSetFieldCases runs each case as a subtest, checking that SetField fails with a
+// ConversionError containing errMsg, or else stores expected in the field.
+func runSetFieldCases(t *testing.T, tests []setFieldCase) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := tt.field()
+			err := SetField(field, tt.value, tt.opts...)
+			if tt.errMsg != "" {
+				assert.ErrorContains(t, err, tt.errMsg)
+				var convErr *ConversionError
+				assert.True(t, errors.As(err, &convErr))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, deref(field))
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func deref(field any) any {
+	return reflect.ValueOf(field).Elem().Interface()
+}
+
+func TestSetFieldNumericRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    func() any
+		value    any
+		lenient  any
+		errMsg   string
+		rangeErr bool
+	}{
+		{
+			name:     "int overflows int8",
+			field:    func() any { return new(int8) },
+			value:    300,
+			lenient:  int8(44),
+			errMsg:   "ctag: cannot convert int to int8: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "int underflows int16",
+			field:    func() any { return new(int16) },
+			value:    int64(math.MinInt16 - 1),
+			lenient:  int16(math.MaxInt16),
+			errMsg:   "ctag: cannot convert int64 to int16: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "negative int to uint",
+			field:    func() any { return new(uint) },
+			value:    -1,
+			lenient:  uint(math.MaxUint),
+			errMsg:   "ctag: cannot convert int to uint: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "uint overflows int64",
+			field:    func() any { return new(int64) },
+			value:    uint64(math.MaxUint64),
+			lenient:  int64(-1),
+			errMsg:   "ctag: cannot convert uint64 to int64: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "uint overflows uint8",
+			field:    func() any { return new(uint8) },
+			value:    uint(256),
+			lenient:  uint8(0),
+			errMsg:   "ctag: cannot convert uint to uint8: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:    "fractional float to int",
+			field:   func() any { return new(int) },
+			value:   3.9,
+			lenient: 3,
+			errMsg:  "ctag: cannot convert float64 to int: 3.9 has a fractional part",
+		},
+		{
+			name:    "fractional float to uint",
+			field:   func() any { return new(uint32) },
+			value:   float32(1.5),
+			lenient: uint32(1),
+			errMsg:  "ctag: cannot convert float32 to uint32: 1.5 has a fractional part",
+		},
+		{
+			name:     "negative float to uint",
+			field:    func() any { return new(uint) },
+			value:    -2.0,
+			errMsg:   "ctag: cannot convert float64 to uint: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "float overflows int32",
+			field:    func() any { return new(int32) },
+			value:    1e10,
+			errMsg:   "ctag: cannot convert float64 to int32: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:   "NaN to int",
+			field:  func() any { return new(int) },
+			value:  math.NaN(),
+			errMsg: "ctag: cannot convert float64 to int: NaN is not a number",
+		},
+		{
+			name:     "infinity to int",
+			field:    func() any { return new(int64) },
+			value:    math.Inf(1),
+			errMsg:   "ctag: cannot convert float64 to int64: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "float64 overflows float32",
+			field:    func() any { return new(float32) },
+			value:    1e39,
+			lenient:  float32(math.Inf(1)),
+			errMsg:   "ctag: cannot convert float64 to float32: value out of range",
+			rangeErr: true,
+		},
+		{
+			name:     "string overflows int8",
+			field:    func() any { return new(int8) },
+			value:    "300",
+			lenient:  int8(44),
+			errMsg:   `ctag: cannot parse "300" as int8: strconv.ParseInt: parsing "300": value out of range`,
+			rangeErr: true,
+		},
+		{
+			name:     "string overflows uint16",
+			field:    func() any { return new(uint16) },
+			value:    "70000",
+			lenient:  uint16(4464),
+			errMsg:   `ctag: cannot parse "70000" as uint16: strconv.ParseUint: parsing "70000": value out of range`,
+			rangeErr: true,
+		},
+		{
+			name:     "string overflows float32",
+			field:    func() any { return new(float32) },
+			value:    "1e39",
+			lenient:  float32(math.Inf(1)),
+			errMsg:   `ctag: cannot parse "1e39" as float32: strconv.ParseFloat: parsing "1e39": value out of range`,
+			rangeErr: true,
+		},
+		{
+			name:   "negative string to uint",
+			field:  func() any { return new(uint) },
+			value:  "-1",
+			errMsg: `ctag: cannot parse "-1" as uint: strconv.ParseUint: parsing "-1": invalid syntax`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field := tt.field()
+			err := SetField(field, tt.value)
+			assert.EqualError(t, err, tt.errMsg)
+			assert.Equal(t, tt.rangeErr, errors.Is(err, strconv.ErrRange))
+
+			if tt.lenient != nil {
+				field = tt.field()
+				assert.NoError(t, SetField(field, tt.value, WithLenientNumbers(true)))
+				assert.Equal(t, tt.lenient, deref(field))
+			}
+		})
+	}
+}
+
+func TestSetFieldNumericBounds(t *testing.T) {
+	tests := []setFieldCase{
+		{name: "max int8", field: func() any { return new(int8) }, value: 127, expected: int8(127)},
+		{name: "min int8", field: func() any { return new(int8) }, value: -128, expected: int8(-128)},
+		{name: "max uint8", field: func() any { return new(uint8) }, value: uint64(255), expected: uint8(255)},
+		{name: "max int64 from uint", field: func() any { return new(int64) }, value: uint64(math.MaxInt64), expected: int64(math.MaxInt64)},
+		{name: "whole float to int8", field: func() any { return new(int8) }, value: -128.0, expected: int8(-128)},
+		{name: "string max uint8", field: func() any { return new(uint8) }, value: "255", expected: uint8(255)},
+		{name: "string min int16", field: func() any { return new(int16) }, value: "-32768", expected: int16(math.MinInt16)},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestSetFieldMap(t *testing.T) {
+	type Labels struct {
+		Team     string `json:"team"`
+		Priority int    `json:"priority"`
+		Internal string `json:"-"`
+		Owner    string
+		hidden   string
+	}
+
+	tests := []setFieldCase{
+		{
+			name:     "string to map",
+			field:    func() any { return new(map[string]string) },
+			value:    "a=1, b = 2",
+			expected: map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:     "string to map with converted values",
+			field:    func() any { return new(map[string]int) },
+			value:    "a=1,b=2",
+			expected: map[string]int{"a": 1, "b": 2},
+		},
+		{
+			name:     "string to map with converted keys",
+			field:    func() any { return new(map[int]bool) },
+			value:    "1=true,2=false",
+			expected: map[int]bool{1: true, 2: false},
+		},
+		{
+			name:     "string value containing equals sign",
+			field:    func() any { return new(map[string]string) },
+			value:    "filter=a=b",
+			expected: map[string]string{"filter": "a=b"},
+		},
+		{
+			name:     "string with repeated keys to slice values",
+			field:    func() any { return new(url.Values) },
+			value:    "tag=a,tag=b,page=1",
+			expected: url.Values{"tag": {"a", "b"}, "page": {"1"}},
+		},
+		{
+			name:     "string with quoted keys and values",
+			field:    func() any { return new(map[string]string) },
+			value:    `"a,b"="x=1,y", c = "d"`,
+			expected: map[string]string{"a,b": "x=1,y", "c": "d"},
+		},
+		{
+			name:     "string with custom separator",
+			field:    func() any { return new(map[string]string) },
+			value:    "a=1,2;b=3",
+			opts:     []SetOption{WithSeparators(";")},
+			expected: map[string]string{"a": "1,2", "b": "3"},
+		},
+		{
+			name:     "string with quoted slice value",
+			field:    func() any { return new(url.Values) },
+			value:    `tag="a,b",tag=c`,
+			expected: url.Values{"tag": {"a,b", "c"}},
+		},
+		{
+			name:   "string with unterminated quote",
+			field:  func() any { return new(map[string]string) },
+			value:  `a="1`,
+			errMsg: `ctag: cannot parse "a=\"1" as map[string]string: unterminated quoted element`,
+		},
+		{
+			name:     "empty string to map",
+			field:    func() any { return new(map[string]string) },
+			value:    "",
+			expected: map[string]string{},
+		},
+		{
+			name:   "string missing separator",
+			field:  func() any { return new(map[string]string) },
+			value:  "a=1,b",
+			errMsg: `ctag: cannot parse "a=1,b" as map[string]string: missing "=" in "b"`,
+		},
+		{
+			name:   "string with invalid value",
+			field:  func() any { return new(map[string]int) },
+			value:  "a=x",
+			errMsg: `ctag: error converting map value for key "a": ctag: cannot parse "x" as int`,
+		},
+		{
+			name:   "string with invalid key",
+			field:  func() any { return new(map[int]string) },
+			value:  "x=1",
+			errMsg: `ctag: error converting map key x: ctag: cannot parse "x" as int`,
+		},
+		{
+			name:     "map to map with key and value coercion",
+			field:    func() any { return new(map[string]float64) },
+			value:    map[int]string{1: "1.5", 2: "2"},
+			expected: map[string]float64{"1": 1.5, "2": 2},
+		},
+		{
+			name:     "map of any to typed map",
+			field:    func() any { return new(map[string]int) },
+			value:    map[string]any{"a": 1, "b": "2", "c": 3.0},
+			expected: map[string]int{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name:     "url values to map of strings",
+			field:    func() any { return new(map[string]string) },
+			value:    url.Values{"b": {"3"}, "c": {}},
+			expected: map[string]string{"b": "3", "c": ""},
+		},
+		{
+			name:   "url values with several values to map of strings",
+			field:  func() any { return new(map[string]string) },
+			value:  url.Values{"a": {"1", "2"}, "b": {"3"}},
+			errMsg: `ctag: error converting map value for key "a": ctag: cannot convert []string to string: 2 values for a single value`,
+		},
+		{
+			name:     "http header to map of slices",
+			field:    func() any { return new(map[string][]int) },
+			value:    http.Header{"X-Ids": {"1", "2"}},
+			expected: map[string][]int{"X-Ids": {1, 2}},
+		},
+		{
+			name:     "map of strings to url values",
+			field:    func() any { return new(url.Values) },
+			value:    map[string]string{"tag": "a,b"},
+			expected: url.Values{"tag": {"a", "b"}},
+		},
+		{
+			name:     "nil map",
+			field:    func() any { m := map[string]int{"a": 1}; return &m },
+			value:    map[string]string(nil),
+			expected: map[string]int(nil),
+		},
+		{
+			name:   "map with invalid value",
+			field:  func() any { return new(map[string]uint) },
+			value:  map[string]int{"a": -1},
+			errMsg: `ctag: error converting map value for key "a": ctag: cannot convert int to uint: value out of range`,
+		},
+		{
+			name:     "struct to map",
+			field:    func() any { return new(map[string]string) },
+			value:    Labels{Team: "core", Priority: 2, Internal: "x", Owner: "me", hidden: "h"},
+			expected: map[string]string{"team": "core", "priority": "2", "Owner": "me"},
+		},
+		{
+			name:     "struct pointer to map of any",
+			field:    func() any { return new(map[string]any) },
+			value:    &Labels{Team: "core", Priority: 2},
+			expected: map[string]any{"team": "core", "priority": 2, "Owner": ""},
+		},
+		{
+			name:   "struct to map with invalid value",
+			field:  func() any { return new(map[string]bool) },
+			value:  Labels{Team: "core"},
+			errMsg: `ctag: cannot parse "core" as bool`,
+		},
+		{
+			name:   "incompatible source",
+			field:  func() any { return new(map[string]string) },
+			value:  42,
+			errMsg: "ctag: cannot convert int to map[string]string",
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestSetFieldMapStructError(t *testing.T) {
+	type Labels struct {
+		Team string `json:"team"`
+	}
+
+	var m map[string]int
+	err := SetField(&m, Labels{Team: "core"})
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Team", fieldErr.Path)
+	assert.Equal(t, "team", fieldErr.Name)
+}
+
+func TestSetFieldArrayAndBytes(t *testing.T) {
+	type ID [4]byte
+
+	tests := []setFieldCase{
+		{
+			name:     "string to int array",
+			field:    func() any { return new([3]int) },
+			value:    "1, 2, 3",
+			expected: [3]int{1, 2, 3},
+		},
+		{
+			name:     "slice to array",
+			field:    func() any { return new([2]string) },
+			value:    []any{"a", 2},
+			expected: [2]string{"a", "2"},
+		},
+		{
+			name:     "array to array",
+			field:    func() any { return new([2]float64) },
+			value:    [2]int{1, 2},
+			expected: [2]float64{1, 2},
+		},
+		{
+			name:     "array to slice",
+			field:    func() any { return new([]int) },
+			value:    [2]string{"1", "2"},
+			expected: []int{1, 2},
+		},
+		{
+			name:     "pointer to array",
+			field:    func() any { return new(*[2]int) },
+			value:    "1,2",
+			expected: &[2]int{1, 2},
+		},
+		{
+			name:   "too few elements",
+			field:  func() any { return new([3]int) },
+			value:  "1,2",
+			errMsg: `ctag: cannot parse "1,2" as [3]int: got 2 elements, want 3`,
+		},
+		{
+			name:   "too many elements",
+			field:  func() any { return new([1]int) },
+			value:  []int{1, 2},
+			errMsg: "ctag: cannot convert []int to [1]int: got 2 elements, want 1",
+		},
+		{
+			name:   "invalid element",
+			field:  func() any { return new([2]int) },
+			value:  "1,x",
+			errMsg: `ctag: error converting slice element 1: ctag: cannot parse "x" as int`,
+		},
+		{
+			name:   "incompatible source",
+			field:  func() any { return new([2]int) },
+			value:  true,
+			errMsg: "ctag: cannot convert bool to [2]int",
+		},
+		{
+			name:     "bytes from numbers",
+			field:    func() any { return new([]byte) },
+			value:    "1,2,3",
+			expected: []byte{1, 2, 3},
+		},
+		{
+			name:     "raw bytes",
+			field:    func() any { return new([]byte) },
+			value:    "hi,there",
+			opts:     []SetOption{WithEncoding(EncodingRaw)},
+			expected: []byte("hi,there"),
+		},
+		{
+			name:     "base64 bytes",
+			field:    func() any { return new([]byte) },
+			value:    "aGVsbG8/Pz4+",
+			opts:     []SetOption{WithEncoding(EncodingBase64)},
+			expected: []byte("hello??>>"),
+		},
+		{
+			name:     "unpadded base64 bytes",
+			field:    func() any { return new([]byte) },
+			value:    "aGk",
+			opts:     []SetOption{WithEncoding(EncodingBase64)},
+			expected: []byte("hi"),
+		},
+		{
+			name:     "base64url bytes",
+			field:    func() any { return new([]byte) },
+			value:    "aGVsbG8_Pz4-",
+			opts:     []SetOption{WithEncoding(EncodingBase64URL)},
+			expected: []byte("hello??>>"),
+		},
+		{
+			name:     "hex bytes",
+			field:    func() any { return new([]byte) },
+			value:    "cafe",
+			opts:     []SetOption{WithEncoding(EncodingHex)},
+			expected: []byte{0xca, 0xfe},
+		},
+		{
+			name:     "hex byte array",
+			field:    func() any { return new(ID) },
+			value:    "0a0b0c0d",
+			opts:     []SetOption{WithEncoding(EncodingHex)},
+			expected: ID{0x0a, 0x0b, 0x0c, 0x0d},
+		},
+		{
+			name:     "raw byte array",
+			field:    func() any { return new([2]byte) },
+			value:    "ok",
+			opts:     []SetOption{WithEncoding(EncodingRaw)},
+			expected: [2]byte{'o', 'k'},
+		},
+		{
+			name:     "byte array from numbers",
+			field:    func() any { return new([2]byte) },
+			value:    "7,8",
+			expected: [2]byte{7, 8},
+		},
+		{
+			name:     "slice of encoded bytes",
+			field:    func() any { return new([][]byte) },
+			value:    "aGk=,eW8=",
+			opts:     []SetOption{WithEncoding(EncodingBase64)},
+			expected: [][]byte{[]byte("hi"), []byte("yo")},
+		},
+		{
+			name:   "short byte array",
+			field:  func() any { return new(ID) },
+			value:  "0a0b",
+			opts:   []SetOption{WithEncoding(EncodingHex)},
+			errMsg: `ctag: cannot parse "0a0b" as ctag.ID: got 2 elements, want 4`,
+		},
+		{
+			name:   "invalid hex",
+			field:  func() any { return new([]byte) },
+			value:  "xyz",
+			opts:   []SetOption{WithEncoding(EncodingHex)},
+			errMsg: `ctag: cannot parse "xyz" as []uint8: encoding/hex: invalid byte`,
+		},
+		{
+			name:   "unknown encoding",
+			field:  func() any { return new([]byte) },
+			value:  "abc",
+			opts:   []SetOption{WithEncoding("base32")},
+			errMsg: `ctag: cannot parse "abc" as []uint8: unknown encoding "base32"`,
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+func TestSetFieldEncodingWithTag(t *testing.T) {
+	type Request struct {
+		Token []byte   `query:"token,enc=base64url"`
+		Hash  [2]byte  `query:"hash,enc=hex"`
+		Raw   []byte   `query:"raw,enc=raw"`
+		Bytes []byte   `query:"bytes"`
+		IDs   [2]int64 `query:"ids"`
+	}
+
+	p := &taggedProcessor{values: map[string]string{
+		"token": "dG9rZW4",
+		"hash":  "beef",
+		"raw":   "raw",
+		"bytes": "1,2,3",
+		"ids":   "7,8",
+	}}
+
+	var request Request
+	_, err := Get("query", &request, WithProcessor(p))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("token"), request.Token)
+	assert.Equal(t, [2]byte{0xbe, 0xef}, request.Hash)
+	assert.Equal(t, []byte("raw"), request.Raw)
+	assert.Equal(t, []byte{1, 2, 3}, request.Bytes)
+	assert.Equal(t, [2]int64{7, 8}, request.IDs)
+}
@@ -0,0 +1,128 @@
+package ctag
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Special time layouts that parse Unix timestamps rather than formatted times.
+const (
+	// LayoutUnix parses a time from a number of seconds since the Unix epoch.
+	LayoutUnix = "unix"
+	// LayoutUnixMilli parses a time from a number of milliseconds since the Unix epoch.
+	LayoutUnixMilli = "unixmilli"
+)
+
+// namedLayouts maps the names of the layout constants of the time package, usable in a
+// layout= tag option, to their layouts.
+var namedLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+}
+
+// setTime sets a time.Duration or time.Time fieldVal from value, and reports whether
+// fieldVal has one of those types and value was handled.
+//
+// Durations are parsed from strings with time.ParseDuration; numbers keep converting as
+// nanoseconds. Times are parsed from strings with the layout set with WithTimeLayout or
+// WithTag, RFC3339 by default, and from numbers as Unix seconds, or milliseconds with
+// LayoutUnixMilli. Times built from Unix timestamps are in UTC.
+func (s *setter) setTime(fieldVal reflect.Value, value any) (bool, error) {
+	switch fieldVal.Type() {
+	case durationType:
+		str, ok := value.(string)
+		if !ok {
+			return false, nil
+		}
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			return true, &ConversionError{Value: value, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.SetInt(int64(d))
+		return true, nil
+	case timeType:
+		t, ok, err := s.parseTime(value)
+		if !ok {
+			return false, nil
+		}
+		if err != nil {
+			return true, &ConversionError{Value: value, Type: fieldVal.Type(), Err: err}
+		}
+		fieldVal.Set(reflect.ValueOf(t))
+		return true, nil
+	}
+	return false, nil
+}
+
+// parseTime converts a string or numeric value to a time, and reports whether value has
+// one of those kinds.
+func (s *setter) parseTime(value any) (time.Time, bool, error) {
+	layout := s.layout
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Kind() == reflect.String:
+		str := v.String()
+		if layout != LayoutUnix && layout != LayoutUnixMilli {
+			if layout == "" {
+				layout = time.RFC3339
+			}
+			t, err := time.Parse(layout, str)
+			return t, true, err
+		}
+		if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return unixIntTime(n, layout), true, nil
+		}
+		n, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return time.Time{}, true, err
+		}
+		return unixTime(n, layout), true, nil
+	case v.CanInt():
+		return unixIntTime(v.Int(), layout), true, nil
+	case v.CanUint():
+		return unixIntTime(int64(v.Uint()), layout), true, nil
+	case v.CanFloat():
+		return unixTime(v.Float(), layout), true, nil
+	}
+	return time.Time{}, false, nil
+}
+
+// unixIntTime returns the UTC time n seconds, or milliseconds with LayoutUnixMilli, after the Unix epoch.
+func unixIntTime(n int64, layout string) time.Time {
+	if layout == LayoutUnixMilli {
+		return time.UnixMilli(n).UTC()
+	}
+	return time.Unix(n, 0).UTC()
+}
+
+// unixTime returns the UTC time n seconds, or milliseconds with LayoutUnixMilli, after the
+// Unix epoch, keeping any fraction down to the nanosecond.
+func unixTime(n float64, layout string) time.Time {
+	if layout == LayoutUnixMilli {
+		n /= 1e3
+	}
+	sec := int64(n)
+	return time.Unix(sec, int64((n-float64(sec))*1e9)).UTC()
+}
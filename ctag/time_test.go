@@ -0,0 +1,166 @@
+package ctag
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFieldTime(t *testing.T) {
+	ts := time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC)
+
+	tests := []setFieldCase{
+		{
+			name:     "duration from string",
+			field:    func() any { return new(time.Duration) },
+			value:    "1m30s",
+			expected: 90 * time.Second,
+		},
+		{
+			name:     "duration from integer nanoseconds",
+			field:    func() any { return new(time.Duration) },
+			value:    1500,
+			expected: 1500 * time.Nanosecond,
+		},
+		{
+			name:     "duration slice",
+			field:    func() any { return new([]time.Duration) },
+			value:    "1s, 2ms",
+			expected: []time.Duration{time.Second, 2 * time.Millisecond},
+		},
+		{
+			name:     "duration pointer",
+			field:    func() any { return new(*time.Duration) },
+			value:    "30s",
+			expected: ptr(30 * time.Second),
+		},
+		{
+			name:   "invalid duration",
+			field:  func() any { return new(time.Duration) },
+			value:  "30",
+			errMsg: `ctag: cannot parse "30" as time.Duration: time: missing unit in duration "30"`,
+		},
+		{
+			name:     "time RFC3339 by default",
+			field:    func() any { return new(time.Time) },
+			value:    "2024-01-31T10:30:00Z",
+			expected: ts,
+		},
+		{
+			name:     "time with layout",
+			field:    func() any { return new(time.Time) },
+			value:    "31/01/2024 10:30",
+			opts:     []SetOption{WithTimeLayout("02/01/2006 15:04")},
+			expected: ts,
+		},
+		{
+			name:     "time with named layout",
+			field:    func() any { return new(time.Time) },
+			value:    "2024-01-31",
+			opts:     []SetOption{WithTimeLayout("DateOnly")},
+			expected: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "time from unix seconds",
+			field:    func() any { return new(time.Time) },
+			value:    ts.Unix(),
+			expected: ts,
+		},
+		{
+			name:     "time from unsigned unix seconds",
+			field:    func() any { return new(time.Time) },
+			value:    uint64(ts.Unix()),
+			expected: ts,
+		},
+		{
+			name:     "time from fractional unix seconds",
+			field:    func() any { return new(time.Time) },
+			value:    float64(ts.Unix()) + 0.5,
+			expected: ts.Add(500 * time.Millisecond),
+		},
+		{
+			name:     "time from unix milliseconds",
+			field:    func() any { return new(time.Time) },
+			value:    ts.UnixMilli() + 250,
+			opts:     []SetOption{WithTimeLayout(LayoutUnixMilli)},
+			expected: ts.Add(250 * time.Millisecond),
+		},
+		{
+			name:     "time from unix string",
+			field:    func() any { return new(time.Time) },
+			value:    "1706697000",
+			opts:     []SetOption{WithTimeLayout(LayoutUnix)},
+			expected: ts,
+		},
+		{
+			name:     "time from unix milliseconds string",
+			field:    func() any { return new(time.Time) },
+			value:    "1706697000000",
+			opts:     []SetOption{WithTimeLayout(LayoutUnixMilli)},
+			expected: ts,
+		},
+		{
+			name:     "time pointer",
+			field:    func() any { return new(*time.Time) },
+			value:    "2024-01-31T10:30:00Z",
+			expected: &ts,
+		},
+		{
+			name:     "time value",
+			field:    func() any { return new(time.Time) },
+			value:    ts,
+			expected: ts,
+		},
+		{
+			name:   "invalid time",
+			field:  func() any { return new(time.Time) },
+			value:  "yesterday",
+			errMsg: `ctag: cannot parse "yesterday" as time.Time`,
+		},
+		{
+			name:   "invalid unix string",
+			field:  func() any { return new(time.Time) },
+			value:  "soon",
+			opts:   []SetOption{WithTimeLayout(LayoutUnix)},
+			errMsg: `ctag: cannot parse "soon" as time.Time`,
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
+
+type taggedProcessor struct {
+	values map[string]string
+}
+
+func (p *taggedProcessor) Process(field any, tag *CTag) error {
+	return SetField(field, p.values[tag.Name], WithTag(tag))
+}
+
+func TestSetFieldTimeWithTag(t *testing.T) {
+	type Request struct {
+		Since   time.Time     `query:"since,layout=DateOnly"`
+		Until   time.Time     `query:"until,layout='Mon, 02 Jan 2006'"`
+		Created time.Time     `query:"created,layout=unix"`
+		Updated time.Time     `query:"updated"`
+		Timeout time.Duration `query:"timeout"`
+	}
+
+	p := &taggedProcessor{values: map[string]string{
+		"since":   "2024-01-31",
+		"until":   "Fri, 02 Feb 2024",
+		"created": "1706697000",
+		"updated": "2024-01-31T10:30:00+01:00",
+		"timeout": "2.5s",
+	}}
+
+	var request Request
+	_, err := Get("query", &request, WithProcessor(p))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), request.Since)
+	assert.Equal(t, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), request.Until)
+	assert.Equal(t, time.Date(2024, 1, 31, 10, 30, 0, 0, time.UTC), request.Created)
+	assert.True(t, time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC).Equal(request.Updated))
+	assert.Equal(t, 2500*time.Millisecond, request.Timeout)
+}
@@ -0,0 +1,123 @@
+package ctag
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"reflect"
+)
+
+// setUnmarshaler sets fieldVal from value through an unmarshaling interface implemented by
+// the field's type, and reports whether one was used. The interfaces are tried in order:
+//
+//   - encoding.TextUnmarshaler, for string and []byte values.
+//   - flag.Value, for string values.
+//   - sql.Scanner, for any other value.
+//   - json.Unmarshaler, for any other value. A []byte or json.RawMessage value is passed as is,
+//     as JSON; any other value, strings included, is encoded with json.Marshal.
+//
+// Maps and structs other than time.Time are never passed to these interfaces: they are left to
+// the built-in conversions, which populate structs and maps from them field by field.
+//
+// Methods with both value and pointer receivers are found when the field is addressable, and
+// methods with value receivers otherwise. Pointer fields are left to setPointerValue, which
+// allocates the value the methods are called on.
+func setUnmarshaler(fieldVal reflect.Value, value any) (bool, error) {
+	if fieldVal.Kind() == reflect.Ptr || fieldVal.Kind() == reflect.Interface {
+		return false, nil
+	}
+	if structured(value) {
+		return false, nil
+	}
+
+	target := fieldVal
+	if fieldVal.CanAddr() {
+		target = fieldVal.Addr()
+	}
+	if !target.CanInterface() {
+		return false, nil
+	}
+
+	text, isText := textValue(value)
+	var err error
+	switch u := target.Interface().(type) {
+	case encoding.TextUnmarshaler:
+		if !isText {
+			return unmarshalOther(target, value)
+		}
+		err = u.UnmarshalText(text)
+	case flag.Value:
+		if _, ok := value.(string); !ok {
+			return unmarshalOther(target, value)
+		}
+		err = u.Set(string(text))
+	default:
+		return unmarshalOther(target, value)
+	}
+	if err != nil {
+		return true, &ConversionError{Value: value, Type: fieldVal.Type(), Err: err}
+	}
+	return true, nil
+}
+
+// unmarshalOther sets the value target points to from value through sql.Scanner or
+// json.Unmarshaler, and reports whether either was used.
+func unmarshalOther(target reflect.Value, value any) (bool, error) {
+	var err error
+	switch u := target.Interface().(type) {
+	case sql.Scanner:
+		err = u.Scan(value)
+	case json.Unmarshaler:
+		var data []byte
+		if data, err = jsonValue(value); err == nil {
+			err = u.UnmarshalJSON(data)
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return true, &ConversionError{Value: value, Type: reflect.Indirect(target).Type(), Err: err}
+	}
+	return true, nil
+}
+
+// structured reports whether value is a map or a struct other than time.Time, or a pointer to
+// one, which SetField converts field by field rather than through an unmarshaling interface.
+func structured(value any) bool {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Struct:
+		return v.Type() != timeType
+	}
+	return false
+}
+
+// textValue returns value as text if it is a string or []byte.
+func textValue(value any) ([]byte, bool) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), true
+	case []byte:
+		return v, true
+	}
+	return nil, false
+}
+
+// jsonValue returns the JSON encoding of value passed to json.Unmarshaler. Raw bytes are
+// taken to be JSON already, while a string is always encoded as a JSON string, even if its
+// text happens to be valid JSON, such as "123" or "null".
+func jsonValue(value any) ([]byte, error) {
+	switch v := value.(type) {
+	case json.RawMessage:
+		return v, nil
+	case []byte:
+		return v, nil
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,198 @@
+package ctag
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+type unmarshalLevel int
+
+func (l *unmarshalLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type unmarshalFlag struct {
+	values []string
+}
+
+func (f *unmarshalFlag) String() string { return strings.Join(f.values, ",") }
+
+func (f *unmarshalFlag) Set(s string) error {
+	f.values = append(f.values, s)
+	return nil
+}
+
+type unmarshalJSON struct {
+	raw string
+}
+
+func (j *unmarshalJSON) UnmarshalJSON(data []byte) error {
+	j.raw = string(data)
+	return nil
+}
+
+type unmarshalJSONString string
+
+func (s *unmarshalJSONString) UnmarshalJSON(data []byte) error {
+	var v string
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = unmarshalJSONString(v)
+	return nil
+}
+
+type unmarshalSet map[string]bool
+
+func (s unmarshalSet) UnmarshalText(text []byte) error {
+	for _, part := range strings.Split(string(text), "|") {
+		s[part] = true
+	}
+	return nil
+}
+
+func TestSetFieldUnmarshalers(t *testing.T) {
+	tests := []setFieldCase{
+		{
+			name:     "text unmarshaler struct",
+			field:    func() any { return new(netip.Addr) },
+			value:    "192.168.0.1",
+			expected: netip.MustParseAddr("192.168.0.1"),
+		},
+		{
+			name:     "text unmarshaler from bytes",
+			field:    func() any { return new(netip.Addr) },
+			value:    []byte("::1"),
+			expected: netip.MustParseAddr("::1"),
+		},
+		{
+			name:     "text unmarshaler through pointer field",
+			field:    func() any { return new(*netip.Addr) },
+			value:    "10.0.0.1",
+			expected: ptr(netip.MustParseAddr("10.0.0.1")),
+		},
+		{
+			name:     "text unmarshaler on numeric kind",
+			field:    func() any { return new(unmarshalLevel) },
+			value:    "high",
+			expected: unmarshalLevel(2),
+		},
+		{
+			name:   "text unmarshaler error",
+			field:  func() any { return new(unmarshalLevel) },
+			value:  "medium",
+			errMsg: `ctag: cannot parse "medium" as ctag.unmarshalLevel: unknown level "medium"`,
+		},
+		{
+			name:     "assignable value skips unmarshaler",
+			field:    func() any { return new(unmarshalLevel) },
+			value:    unmarshalLevel(1),
+			expected: unmarshalLevel(1),
+		},
+		{
+			name:     "value receiver",
+			field:    func() any { s := unmarshalSet{}; return &s },
+			value:    "a|b",
+			expected: unmarshalSet{"a": true, "b": true},
+		},
+		{
+			name:     "flag value",
+			field:    func() any { return new(unmarshalFlag) },
+			value:    "x",
+			expected: unmarshalFlag{values: []string{"x"}},
+		},
+		{
+			name:     "sql scanner",
+			field:    func() any { return new(sql.NullInt64) },
+			value:    int64(42),
+			expected: sql.NullInt64{Int64: 42, Valid: true},
+		},
+		{
+			name:     "sql scanner from string",
+			field:    func() any { return new(sql.NullString) },
+			value:    "hello",
+			expected: sql.NullString{String: "hello", Valid: true},
+		},
+		{
+			name:   "sql scanner error",
+			field:  func() any { return new(sql.NullInt64) },
+			value:  "abc",
+			errMsg: `ctag: cannot parse "abc" as sql.NullInt64`,
+		},
+		{
+			name:     "json unmarshaler from plain string",
+			field:    func() any { return new(unmarshalJSON) },
+			value:    "hello",
+			expected: unmarshalJSON{raw: `"hello"`},
+		},
+		{
+			name:     "json unmarshaler from json text string",
+			field:    func() any { return new(unmarshalJSON) },
+			value:    `{"a":1}`,
+			expected: unmarshalJSON{raw: `"{\"a\":1}"`},
+		},
+		{
+			name:     "json unmarshaler from bytes",
+			field:    func() any { return new(unmarshalJSON) },
+			value:    []byte(`{"a":1}`),
+			expected: unmarshalJSON{raw: `{"a":1}`},
+		},
+		{
+			name:     "json unmarshaler from raw message",
+			field:    func() any { return new(unmarshalJSON) },
+			value:    json.RawMessage(`[1,2]`),
+			expected: unmarshalJSON{raw: `[1,2]`},
+		},
+		{
+			name:     "json string type from numeric string",
+			field:    func() any { return new(unmarshalJSONString) },
+			value:    "123",
+			expected: unmarshalJSONString("123"),
+		},
+		{
+			name:     "json string type from null string",
+			field:    func() any { return new(unmarshalJSONString) },
+			value:    "null",
+			expected: unmarshalJSONString("null"),
+		},
+		{
+			name:     "json unmarshaler from value",
+			field:    func() any { return new(unmarshalJSON) },
+			value:    []int{1, 2},
+			expected: unmarshalJSON{raw: `[1,2]`},
+		},
+		{
+			name:     "sql scanner struct from map",
+			field:    func() any { return new(sql.NullString) },
+			value:    map[string]any{"String": "x", "Valid": true},
+			expected: sql.NullString{String: "x", Valid: true},
+		},
+		{
+			name:     "sql scanner from time",
+			field:    func() any { return new(sql.NullTime) },
+			value:    time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			expected: sql.NullTime{Time: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+		},
+		{
+			name:     "slice of text unmarshalers",
+			field:    func() any { return new([]netip.Addr) },
+			value:    "10.0.0.1, 10.0.0.2",
+			expected: []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")},
+		},
+	}
+
+	runSetFieldCases(t, tests)
+}
@@ -0,0 +1,275 @@
+package ctag
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ValidateKey is the tag key holding the validation rules of a field, as in
+// `validate:"required,min=1,max=64"`.
+const ValidateKey = "validate"
+
+// Rule checks a field against one rule of a validate tag. It returns an error describing
+// the violation, or nil if the field satisfies the rule. An error wrapping ErrUnknownField
+// reports a mistake in the tag rather than a violation. Rules are registered by name in a
+// Validator, which calls them for each field whose tag names them.
+//
+// Example usage:
+//
+//	type EvenRule struct{}
+//
+//	func (EvenRule) Validate(ctx *RuleContext) error {
+//	    if n, ok := ctx.Field.(int); ok && n%2 != 0 {
+//	        return errors.New("must be even")
+//	    }
+//	    return nil
+//	}
+type Rule interface {
+	Validate(ctx *RuleContext) error // Validate returns the violation of the rule by the field in ctx, if any.
+}
+
+// RuleFunc is an adapter allowing an ordinary function to be used as a Rule.
+//
+// Example usage:
+//
+//	DefaultValidator.Register("even", RuleFunc(func(ctx *RuleContext) error {
+//	    if n, ok := ctx.Field.(int); ok && n%2 != 0 {
+//	        return errors.New("must be even")
+//	    }
+//	    return nil
+//	}))
+type RuleFunc func(ctx *RuleContext) error
+
+// Validate calls f(ctx).
+func (f RuleFunc) Validate(ctx *RuleContext) error {
+	return f(ctx)
+}
+
+// RuleContext describes the field a Rule is applied to.
+//
+// Fields:
+//
+//	Field - The value of the field, with pointers dereferenced, or nil for a nil pointer.
+//	Param - The parameter of the rule, the text after its first '=', such as "1" in min=1.
+//	Tag   - The validate tag of the field.
+type RuleContext struct {
+	Field  any           // Field is the value of the field, with pointers dereferenced.
+	Param  string        // Param is the parameter of the rule.
+	Tag    *CTag         // Tag is the validate tag of the field.
+	value  reflect.Value // value is the field as declared, before pointers are dereferenced.
+	parent reflect.Value // parent is the struct declaring the field, if known.
+}
+
+// FieldByName returns the value of the field with the given Go name in the struct declaring
+// the validated field, with pointers dereferenced, for rules that compare fields. It returns
+// false if there is no such exported field, and nil if the field is promoted from an embedded
+// struct behind a nil pointer.
+//
+// Example usage:
+//
+//	password, ok := ctx.FieldByName("Password")
+func (c *RuleContext) FieldByName(name string) (any, bool) {
+	if !c.parent.IsValid() {
+		return nil, false
+	}
+	sf, ok := c.parent.Type().FieldByName(name)
+	if !ok || !sf.IsExported() {
+		return nil, false
+	}
+	f, err := c.parent.FieldByIndexErr(sf.Index)
+	if err != nil {
+		return nil, true
+	}
+	if !f.CanInterface() {
+		return nil, false
+	}
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil, true
+		}
+		f = f.Elem()
+	}
+	return f.Interface(), true
+}
+
+// Validator is a registry of validation rules keyed by the names used in validate tags.
+// It is safe for concurrent use.
+//
+// Example usage:
+//
+//	v := NewValidator()
+//	v.Register("even", RuleFunc(func(ctx *RuleContext) error {
+//	    return checkEven(ctx.Field)
+//	}))
+//
+//	err := v.Validate(&request)
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]Rule
+}
+
+// DefaultValidator is the Validator used by Validate. Rules registered here apply globally;
+// use NewValidator for an independent set of rules.
+var DefaultValidator = NewValidator()
+
+// NewValidator returns a Validator with the built-in rules registered:
+//
+//	required    - the field is not its zero value; a pointer to a zero value is set
+//	required_if - the field is required if every named field has the given value, as in required_if=Status active
+//	min, max    - numbers are at least or at most the parameter, strings, slices and maps have at
+//	              least or at most that many characters or elements; durations accept "1s"
+//	len         - numbers equal the parameter, strings, slices and maps have exactly that length
+//	oneof       - the field, formatted with fmt.Sprint, is one of the space-separated parameter values
+//	regex       - the string matches the regular expression in the parameter
+//	eqfield     - the field equals the named field of the same struct
+//	nefield     - the field differs from the named field of the same struct
+//
+// The min, max, len, oneof and regex rules pass nil pointers, leaving it to required to reject them.
+func NewValidator() *Validator {
+	v := &Validator{rules: make(map[string]Rule)}
+	for name, rule := range builtinRules {
+		v.rules[name] = rule
+	}
+	return v
+}
+
+// Register registers rule under name, replacing any rule already registered with that name,
+// including a built-in one. A nil rule removes the registration.
+func (v *Validator) Register(name string, rule Rule) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if rule == nil {
+		delete(v.rules, name)
+		return
+	}
+	v.rules[name] = rule
+}
+
+// Lookup returns the rule registered under name.
+func (v *Validator) Lookup(name string) (Rule, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	rule, ok := v.rules[name]
+	return rule, ok
+}
+
+// Validate checks every field of the struct data against the rules of its validate tag and
+// returns every violation rather than stopping at the first.
+//
+// Each comma-separated item of a tag names a rule, optionally followed by '=' and a parameter,
+// which may be single-quoted to contain commas, as in `validate:"required,regex='^[a-z]{2,8}$'"`.
+// A field tagged omitempty is only checked when it is not its zero value. Nested structs, and
+// structs held in slices, arrays and maps, are validated too.
+//
+// Parameters:
+//
+//	data - the struct to validate, or a pointer to one
+//
+// Returns:
+//
+//	Nil if every field is valid, or ValidationErrors listing every violation, in field order.
+//	If a tag is malformed, names an unknown rule or compares with an unknown field, a FieldErrors
+//	describing the faulty tags is returned instead, matching ErrUnknownRule for unknown rules and
+//	ErrUnknownField for unknown fields.
+//
+// Example usage:
+//
+//	type SignUp struct {
+//	    Name     string `validate:"required,max=64"`
+//	    Plan     string `validate:"oneof=free pro"`
+//	    Password string `validate:"required,min=12"`
+//	    Confirm  string `validate:"eqfield=Password"`
+//	}
+//
+//	err := DefaultValidator.Validate(&signUp)
+func (v *Validator) Validate(data any) error {
+	p := &validateProcessor{validator: v}
+	_, err := Get(ValidateKey, data,
+		WithProcessor(p),
+		WithCollections(true),
+		WithOmitEmpty(OmitEmptyNever),
+		WithErrorMode(CollectErrors),
+	)
+	if err != nil {
+		return err
+	}
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
+}
+
+// Validate checks the struct data against its validate tags with DefaultValidator.
+// See Validator.Validate.
+//
+// Example usage:
+//
+//	type Query struct {
+//	    Limit int    `validate:"min=1,max=100"`
+//	    Sort  string `validate:"omitempty,oneof=asc desc"`
+//	}
+//
+//	if err := Validate(query); err != nil {
+//	    return err
+//	}
+func Validate(data any) error {
+	return DefaultValidator.Validate(data)
+}
+
+// validateProcessor is the TagProcessor of Validator.Validate, collecting the violations
+// of every field.
+type validateProcessor struct {
+	validator *Validator       // validator holds the rules the tags name.
+	errs      ValidationErrors // errs are the violations found so far.
+}
+
+// Process validates the field described by tag without access to the struct declaring it.
+func (p *validateProcessor) Process(field any, tag *CTag) error {
+	return p.processIn(reflect.Value{}, field, tag)
+}
+
+// processIn validates the field described by tag against each rule of the tag, recording
+// violations in p.errs. It returns an error only if the tag names an unknown rule, or a rule
+// names an unknown field.
+func (p *validateProcessor) processIn(parent reflect.Value, field any, tag *CTag) error {
+	items := append([]string{tag.Name}, tag.Options...)
+	names := make([]string, len(items))
+	rules := make([]Rule, len(items))
+	for i, item := range items {
+		name, _, _ := strings.Cut(item, "=")
+		if name == "" || name == "omitempty" {
+			continue
+		}
+		rule, ok := p.validator.Lookup(name)
+		if !ok {
+			return fmt.Errorf("%w %q", ErrUnknownRule, name)
+		}
+		names[i], rules[i] = name, rule
+	}
+
+	value := reflect.ValueOf(tag.Field)
+	if parent.IsValid() {
+		value = parent.Field(tag.StructField.Index[0])
+	}
+	if slices.Contains(items, "omitempty") && (!value.IsValid() || value.IsZero()) {
+		return nil
+	}
+
+	for i, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		_, param, _ := strings.Cut(items[i], "=")
+		ctx := &RuleContext{Field: tag.Field, Param: param, Tag: tag, value: value, parent: parent}
+		if err := rule.Validate(ctx); errors.Is(err, ErrUnknownField) {
+			return err
+		} else if err != nil {
+			p.errs = append(p.errs, &ValidationError{Path: tag.Path, Rule: names[i], Param: param, Value: tag.Field, Err: err})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package ctag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateItem struct {
+	SKU string `validate:"required,regex='^[A-Z]{3}-[0-9]{1,4}$'"`
+	Qty int    `validate:"min=1,max=99"`
+}
+
+type validateAddress struct {
+	Country string `validate:"required,len=2"`
+	State   string `validate:"required_if=Country US"`
+}
+
+type validateOrder struct {
+	ID       int     `validate:"required"`
+	Status   string  `validate:"oneof=pending paid shipped"`
+	Note     string  `validate:"omitempty,min=3"`
+	Email    *string `validate:"omitempty,regex=@"`
+	Address  validateAddress
+	Billing  *validateAddress        `validate:"required"`
+	Items    []validateItem          `validate:"min=1"`
+	Extras   map[string]validateItem `validate:"max=2"`
+	Password string                  `validate:"min=8"`
+	Confirm  string                  `validate:"eqfield=Password"`
+}
+
+func validOrder() validateOrder {
+	return validateOrder{
+		ID:       1,
+		Status:   "paid",
+		Address:  validateAddress{Country: "US", State: "CA"},
+		Billing:  &validateAddress{Country: "DE"},
+		Items:    []validateItem{{SKU: "ABC-1", Qty: 2}},
+		Password: "correct horse",
+		Confirm:  "correct horse",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(o *validateOrder)
+		expected []string
+	}{
+		{
+			name:   "valid",
+			modify: func(o *validateOrder) {},
+		},
+		{
+			name: "collects every violation with paths",
+			modify: func(o *validateOrder) {
+				o.ID = 0
+				o.Status = "lost"
+				o.Note = "ok"
+				o.Address = validateAddress{Country: "US"}
+				o.Billing = nil
+				o.Items = []validateItem{{SKU: "abc", Qty: 0}, {SKU: "ABC-2", Qty: 100}}
+				o.Extras = map[string]validateItem{"gift": {Qty: 1}}
+				o.Confirm = "wrong"
+			},
+			expected: []string{
+				"ctag: field ID failed required: is required",
+				"ctag: field Status failed oneof=pending paid shipped: must be one of pending, paid, shipped",
+				"ctag: field Note failed min=3: length must be at least 3",
+				"ctag: field Address.State failed required_if=Country US: is required when Country is US",
+				"ctag: field Billing failed required: is required",
+				"ctag: field Items[0].SKU failed regex=^[A-Z]{3}-[0-9]{1,4}$: must match ^[A-Z]{3}-[0-9]{1,4}$",
+				"ctag: field Items[0].Qty failed min=1: must be at least 1",
+				"ctag: field Items[1].Qty failed max=99: must be at most 99",
+				`ctag: field Extras["gift"].SKU failed required: is required`,
+				`ctag: field Extras["gift"].SKU failed regex=^[A-Z]{3}-[0-9]{1,4}$: must match ^[A-Z]{3}-[0-9]{1,4}$`,
+				"ctag: field Confirm failed eqfield=Password: must equal Password",
+			},
+		},
+		{
+			name: "omitempty skips zero values only",
+			modify: func(o *validateOrder) {
+				o.Email = ptr("nobody")
+			},
+			expected: []string{"ctag: field Email failed regex=@: must match @"},
+		},
+		{
+			name: "nested pointer struct is validated",
+			modify: func(o *validateOrder) {
+				o.Billing = &validateAddress{Country: "USA"}
+			},
+			expected: []string{"ctag: field Billing.Country failed len=2: length must be exactly 2"},
+		},
+		{
+			name: "collection length",
+			modify: func(o *validateOrder) {
+				o.Items = nil
+			},
+			expected: []string{"ctag: field Items failed min=1: length must be at least 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := validOrder()
+			tt.modify(&order)
+
+			err := Validate(&order)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErrs ValidationErrors
+			assert.True(t, errors.As(err, &validationErrs))
+			msgs := make([]string, len(validationErrs))
+			for i, ve := range validationErrs {
+				msgs[i] = ve.Error()
+			}
+			assert.Equal(t, tt.expected, msgs)
+		})
+	}
+}
+
+func TestValidateByValue(t *testing.T) {
+	order := validOrder()
+	assert.NoError(t, Validate(order))
+
+	order.Confirm = ""
+	err := Validate(order)
+	var validationErr *ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "Confirm", validationErr.Path)
+	assert.Equal(t, "eqfield", validationErr.Rule)
+	assert.Equal(t, "Password", validationErr.Param)
+	assert.Equal(t, "", validationErr.Value)
+}
+
+func TestValidateNilEmbeddedField(t *testing.T) {
+	type Base struct {
+		Password string
+	}
+	type SignUp struct {
+		*Base
+		Confirm string `validate:"eqfield=Password"`
+		Code    string `validate:"required_if=Password secret"`
+	}
+
+	err := Validate(SignUp{Confirm: "secret"})
+	assert.EqualError(t, err, "ctag: field Confirm failed eqfield=Password: must equal Password")
+
+	assert.NoError(t, Validate(SignUp{Base: &Base{Password: "hunter2"}, Confirm: "hunter2"}))
+}
+
+func TestValidatorRegister(t *testing.T) {
+	type Input struct {
+		Count int `validate:"even,max=10"`
+	}
+
+	v := NewValidator()
+	err := v.Validate(Input{Count: 3})
+	assert.ErrorIs(t, err, ErrUnknownRule)
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.Equal(t, "Count", fieldErrs[0].Path)
+
+	v.Register("even", RuleFunc(func(ctx *RuleContext) error {
+		if ctx.Field.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	}))
+	_, ok := v.Lookup("even")
+	assert.True(t, ok)
+	assert.NoError(t, v.Validate(Input{Count: 4}))
+	assert.EqualError(t, v.Validate(Input{Count: 13}),
+		"ctag: field Count failed even: must be even\nctag: field Count failed max=10: must be at most 10")
+
+	v.Register("max", nil)
+	_, ok = v.Lookup("max")
+	assert.False(t, ok)
+	_, ok = DefaultValidator.Lookup("max")
+	assert.True(t, ok)
+	assert.ErrorIs(t, v.Validate(Input{Count: 4}), ErrUnknownRule)
+}
+
+func TestValidateMalformedTag(t *testing.T) {
+	input := struct {
+		Name string `validate:"regex='^a'b"`
+	}{}
+
+	err := Validate(input)
+	var fieldErrs FieldErrors
+	assert.True(t, errors.As(err, &fieldErrs))
+	assert.ErrorContains(t, err, "after quoted value")
+}
+
+func TestValidateNotStruct(t *testing.T) {
+	assert.ErrorIs(t, Validate(42), ErrNotStruct)
+}